@@ -50,7 +50,7 @@ func (cl *Client) makeRequest(method, path string) (*http.Response, error) {
 
 	res, err := cl.httpClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "fintoc: Client.makeRequest cl.httpClient.Do error")
+		return nil, errors.Wrap(errors.ClassifyTransport(err), "fintoc: Client.makeRequest cl.httpClient.Do error")
 	}
 
 	return res, nil
@@ -68,7 +68,7 @@ func (cl *Client) scanBody(res *http.Response, scanner interface{}) error {
 
 		return json.Unmarshal(body, scanner)
 	case http.StatusInternalServerError:
-		return errors.New("internal server error")
+		return errors.WithKind(errors.New("internal server error"), errors.KindFromHTTPCode(res.StatusCode))
 	case http.StatusBadRequest:
 		defer res.Body.Close()
 
@@ -77,7 +77,11 @@ func (cl *Client) scanBody(res *http.Response, scanner interface{}) error {
 			return errors.Wrap(err, "fintoc: Client.scanBody ioutil.ReadAll error")
 		}
 
-		return errors.Wrap(errors.New("bad request"), fmt.Sprintf("body: %s", string(body)))
+		// the body may echo account data, so it's only attached as a sensitive field
+		return errors.WithSensitiveField(
+			errors.WithKind(errors.New("bad request"), errors.Invalid),
+			"body", string(body),
+		)
 	default:
 		defer res.Body.Close()
 
@@ -86,6 +90,12 @@ func (cl *Client) scanBody(res *http.Response, scanner interface{}) error {
 			return errors.Wrap(err, "fintoc: Client.scanBody ioutil.ReadAll error")
 		}
 
-		return errors.New(fmt.Sprintf("unsupported status code %d with body: %s", res.StatusCode, string(body)))
+		return errors.WithSensitiveField(
+			errors.WithKind(
+				errors.New(fmt.Sprintf("unsupported status code %d", res.StatusCode)),
+				errors.KindFromHTTPCode(res.StatusCode),
+			),
+			"body", string(body),
+		)
 	}
 }
@@ -1,8 +1,12 @@
 package fintoc
 
 import (
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/mtavano/devkit/errors"
+	"github.com/mtavano/devkit/test"
 	"github.com/stretchr/testify/require"
 )
 
@@ -10,3 +14,68 @@ func Test_NewClient(t *testing.T) {
 	cl := NewClient("", "", "", "", nil)
 	require.NotNil(t, cl)
 }
+
+func Test_GetAccountMovements_Errors(t *testing.T) {
+	cl := NewClient("", "", "", "", &mockHTTPClient{
+		res: test.CreateMockResponse(`{"error": {"type": "invalid_request_error"}}`, http.StatusBadRequest),
+	})
+	cl.accountID = "acc_123"
+
+	movements, pages, err := cl.GetAccountMovements(&GetAccountMovementsRequest{MaxItems: 10})
+	require.Nil(t, movements)
+	require.Nil(t, pages)
+	require.Error(t, err)
+	require.Equal(t, errors.Invalid, errors.KindOf(err))
+	require.Equal(t, "acc_123", errors.Fields(err)["fintoc_account_id"])
+}
+
+func Test_GetAccountMovementsRequest_Validate(t *testing.T) {
+	since := time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)
+	until := since.Add(-24 * time.Hour)
+
+	testCases := []struct {
+		name           string
+		req            *GetAccountMovementsRequest
+		expectedFields []string
+	}{
+		{
+			name: "should accept valid requests",
+			req:  &GetAccountMovementsRequest{MaxItems: 300, Since: &until, Until: &since},
+		},
+		{
+			name:           "should reject empty pages",
+			req:            &GetAccountMovementsRequest{},
+			expectedFields: []string{"/max_items"},
+		},
+		{
+			name:           "should report every problem",
+			req:            &GetAccountMovementsRequest{MaxItems: 301, Since: &since, Until: &until},
+			expectedFields: []string{"/max_items", "/since"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if tc.expectedFields == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Equal(t, errors.Invalid, errors.KindOf(err))
+			validation, ok := errors.ValidationErrorsOf(err)
+			require.True(t, ok)
+
+			fields := []string{}
+			for _, fe := range validation {
+				fields = append(fields, fe.Field)
+			}
+			require.Equal(t, tc.expectedFields, fields)
+		})
+	}
+
+	// invalid requests never reach the api
+	cl := NewClient("", "", "", "", &mockHTTPClient{})
+	_, _, err := cl.GetAccountMovements(&GetAccountMovementsRequest{})
+	require.Equal(t, http.StatusBadRequest, errors.HTTPCode(err))
+}
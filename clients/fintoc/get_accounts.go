@@ -13,7 +13,7 @@ const (
 )
 
 var (
-	ErrAccountNotFound = errors.New("Account not found")
+	ErrAccountNotFound = errors.WithKind(errors.New("Account not found"), errors.NotFound)
 )
 
 // GetAccountMovements will fetch the
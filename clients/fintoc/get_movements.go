@@ -13,6 +13,9 @@ import (
 
 const (
 	getAccountMovementsURL = "/accounts/%s/movements?%s"
+
+	// maxMovementsPerPage is the largest page fintoc serves
+	maxMovementsPerPage = 300
 )
 
 type GetAccountMovementsRequest struct {
@@ -21,10 +24,29 @@ type GetAccountMovementsRequest struct {
 	Until    *time.Time
 }
 
+// Validate reports every problem of the request at once.
+func (req *GetAccountMovementsRequest) Validate() error {
+	var errs errors.ValidationErrors
+	if req.MaxItems <= 0 {
+		errs.Add(errors.FieldPath("max_items"), "min", "must be greater than 0")
+	}
+	if req.MaxItems > maxMovementsPerPage {
+		errs.Addf(errors.FieldPath("max_items"), "max", "must be at most %d", maxMovementsPerPage)
+	}
+	if req.Since != nil && req.Until != nil && req.Since.After(*req.Until) {
+		errs.Add(errors.FieldPath("since"), "before_until", "must not be after until")
+	}
+
+	return errs.Err()
+}
+
 // GetAccountMovements will fetch the
 func (cl *Client) GetAccountMovements(req *GetAccountMovementsRequest) ([]*Movement, *Pages, error) {
 	if req == nil {
-		return nil, nil, errors.New("fintoc: Client.GetAccountMovements invalid request error")
+		return nil, nil, errors.WithKind(errors.New("fintoc: Client.GetAccountMovements invalid request error"), errors.Invalid)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, nil, errors.Wrap(err, "fintoc: Client.GetAccountMovements req.Validate error")
 	}
 
 	urlValues := url.Values{}
@@ -38,18 +60,18 @@ func (cl *Client) GetAccountMovements(req *GetAccountMovementsRequest) ([]*Movem
 
 	res, err := cl.makeRequest(http.MethodGet, path)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "fintoc: Client.GetAccountMovements cl.makeRequest error")
+		return nil, nil, errors.WithField(errors.Wrap(err, "fintoc: Client.GetAccountMovements cl.makeRequest error"), "fintoc_account_id", cl.accountID)
 	}
 
 	movements := make([]*Movement, 0)
 	err = cl.scanBody(res, &movements)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "fintoc: Client.GetAccountMovements cl.scanBody error")
+		return nil, nil, errors.WithField(errors.Wrap(err, "fintoc: Client.GetAccountMovements cl.scanBody error"), "fintoc_account_id", cl.accountID)
 	}
 
 	nextPageLink, lastPageLink, err := getLinkFromString(res.Header.Get("Link"))
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "fintoc: Client.GetAccountMovements getLinkFromString error")
+		return nil, nil, errors.WithField(errors.Wrap(err, "fintoc: Client.GetAccountMovements getLinkFromString error"), "fintoc_account_id", cl.accountID)
 	}
 
 	return movements, &Pages{
@@ -61,7 +83,7 @@ func (cl *Client) GetAccountMovements(req *GetAccountMovementsRequest) ([]*Movem
 func (cl *Client) GetAccountMovementsByPage(path string) ([]*Movement, *Pages, error) {
 	urlValues := url.Values{}
 	urlValues.Add("link_token", cl.linkToken)
-	urlValues.Add("per_page", fmt.Sprintf("%d", 300))
+	urlValues.Add("per_page", fmt.Sprintf("%d", maxMovementsPerPage))
 
 	res, err := cl.makeRequest(http.MethodGet, path)
 	if err != nil {
@@ -16,7 +16,7 @@ import (
 )
 
 var (
-	ErrFireblocksUnauthorized = errors.New("Unauthorized access")
+	ErrFireblocksUnauthorized = errors.WithKind(errors.New("Unauthorized access"), errors.Unauthorized)
 )
 
 type Client struct {
@@ -69,7 +69,7 @@ func (cl *Client) makeRequest(method string, path string, body []byte) (*http.Re
 
 	res, err := cl.httpClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "fireblocks: Client.makeRequest cl.httpClient.Do error")
+		return nil, errors.Wrap(errors.ClassifyTransport(err), "fireblocks: Client.makeRequest cl.httpClient.Do error")
 	}
 
 	if err := cl.checkStatusCode(res); err != nil {
@@ -90,8 +90,11 @@ func (cl *Client) checkStatusCode(res *http.Response) error {
 	}
 	defer res.Body.Close()
 
-	errorMessage := fmt.Sprintf("fireblocks: unknown status code %d with body: %s", res.StatusCode, string(body))
-	return errors.New(errorMessage)
+	errorMessage := fmt.Sprintf("fireblocks: unknown status code %d", res.StatusCode)
+	return errors.WithSensitiveField(
+		errors.WithKind(errors.New(errorMessage), errors.KindFromHTTPCode(res.StatusCode)),
+		"body", string(body),
+	)
 }
 
 func (cl *Client) scanBody(res *http.Response, scanner interface{}) error {
@@ -135,7 +138,10 @@ func (cl *Client) signJWT(path string, bodyJson []byte) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
 	signKey, err := jwt.ParseRSAPrivateKeyFromPEM(cl.privateKey)
 	if err != nil {
-		return "", errors.Wrap(err, "fireblocks: Client.signJWT jwt.ParseRSAPrivateKeyFromPEM error")
+		return "", errors.WithKind(
+			errors.Wrap(err, "fireblocks: Client.signJWT jwt.ParseRSAPrivateKeyFromPEM error"),
+			errors.Internal,
+		)
 	}
 
 	return token.SignedString(signKey)
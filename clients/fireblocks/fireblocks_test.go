@@ -5,8 +5,11 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"net/http"
 	"testing"
 
+	"github.com/mtavano/devkit/errors"
+	"github.com/mtavano/devkit/test"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,6 +44,40 @@ func Test_NewClient_signJWT(t *testing.T) {
 	require.Equal(t, true, tokenToValidate.Valid)
 }
 
+func Test_Client_checkStatusCode(t *testing.T) {
+	testCases := []struct {
+		name         string
+		res          *http.Response
+		expectedKind errors.Kind
+	}{
+		{
+			name:         "should tag unauthorized responses",
+			res:          test.CreateMockResponse(`{"message": "Unauthorized"}`, http.StatusUnauthorized),
+			expectedKind: errors.Unauthorized,
+		},
+		{
+			name:         "should tag not found responses",
+			res:          test.CreateMockResponse(`{"message": "Not found"}`, http.StatusNotFound),
+			expectedKind: errors.NotFound,
+		},
+		{
+			name:         "should tag rate limited responses",
+			res:          test.CreateMockResponse(`{"message": "Too many requests"}`, http.StatusTooManyRequests),
+			expectedKind: errors.RateLimited,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cl := NewClient("", "", nil)
+
+			err := cl.checkStatusCode(tc.res)
+			require.Error(t, err)
+			require.Equal(t, tc.expectedKind, errors.KindOf(err))
+		})
+	}
+}
+
 // PublicKeyToBytes public key to bytes
 func PublicKeyToBytes(t *testing.T, pub *rsa.PublicKey) []byte {
 	pubASN1, err := x509.MarshalPKIXPublicKey(pub)
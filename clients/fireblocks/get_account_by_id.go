@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/pkg/errors"
+	"github.com/mtavano/devkit/errors"
 )
 
 const (
@@ -14,11 +14,11 @@ const (
 func (cl *Client) GetAccount(id string) (*VaultAccount, error) {
 	resp, err := cl.makeRequest(http.MethodGet, fmt.Sprintf(getAccountByIdURL, id), nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "fireblocks: could not create getAccounts request")
+		return nil, errors.WithField(errors.Wrap(err, "fireblocks: could not create getAccounts request"), "fireblocks_vault_id", id)
 	}
 	var vault VaultAccount
 	if err := cl.scanBody(resp, &vault); err != nil {
-		return nil, errors.Wrap(err, "fireblocks: cl.scanBody: could not scan body into struct")
+		return nil, errors.WithField(errors.Wrap(err, "fireblocks: cl.scanBody: could not scan body into struct"), "fireblocks_vault_id", id)
 	}
 
 	return &vault, nil
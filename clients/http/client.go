@@ -2,12 +2,21 @@ package http
 
 import (
 	"context"
+	"io"
 	nativehttp "net/http"
+	"strconv"
+	"time"
 
 	"github.com/mtavano/devkit/errors"
 	"golang.org/x/time/rate"
 )
 
+var (
+	// ErrBudgetExhausted is returned when the remaining context deadline is not enough to start
+	// a new attempt.
+	ErrBudgetExhausted = errors.WithKind(errors.New("http: timeout budget exhausted"), errors.Timeout)
+)
+
 type BaseHTTPClient interface {
 	Do(*nativehttp.Request) (*nativehttp.Response, error)
 }
@@ -18,47 +27,179 @@ type Client struct {
 	httpClient BaseHTTPClient
 	rl         *rate.Limiter
 
+	maxRetries        int
+	retryReserve      time.Duration
+	minAttemptTimeout time.Duration
+	budgetHeader      string
+
 	validate ValidatorFunc
 }
 
 type Options struct {
 	MaxRequest      int
 	WindowInSeconds int
+
+	// MaxRetries is the number of extra attempts made when a request fails on the transport.
+	MaxRetries int
+	// RetryReserve is the part of the remaining deadline kept aside for retries while there are
+	// retries left. An attempt gets the remaining budget minus this reserve.
+	RetryReserve time.Duration
+	// MinAttemptTimeout is the smallest budget an attempt can start with. Attempts that can't get
+	// it fail with ErrBudgetExhausted instead of being sent.
+	MinAttemptTimeout time.Duration
+	// BudgetHeader, when set, is the header used to send the attempt budget in milliseconds
+	// to the upstream service.
+	BudgetHeader string
 }
 
 func NewClient(opts *Options, client BaseHTTPClient) *Client {
 	rl := rate.NewLimiter(rate.Limit(opts.MaxRequest), opts.WindowInSeconds)
 
 	return &Client{
-		httpClient: client,
-		rl:         rl,
+		httpClient:        client,
+		rl:                rl,
+		maxRetries:        opts.MaxRetries,
+		retryReserve:      opts.RetryReserve,
+		minAttemptTimeout: opts.MinAttemptTimeout,
+		budgetHeader:      opts.BudgetHeader,
 	}
 }
 
+// Do sends the request honoring the deadline of its context. When the context has a deadline
+// every attempt gets its own timeout derived from the remaining budget, keeping RetryReserve
+// aside while there are retries left. Requests whose body can't be replayed, without GetBody,
+// are sent once.
 func (cl *Client) Do(req *nativehttp.Request) (res *nativehttp.Response, err error) {
-	ctx := context.Background()
+	ctx := req.Context()
+
+	maxRetries := cl.maxRetries
+	if req.Body != nil && req.Body != nativehttp.NoBody && req.GetBody == nil {
+		// the body is consumed by the first attempt and can't be replayed
+		maxRetries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		retriesLeft := maxRetries - attempt
+
+		var retry bool
+		res, retry, err = cl.doAttempt(ctx, req, attempt, retriesLeft, err)
+		if err == nil || !retry || retriesLeft <= 0 || ctx.Err() != nil {
+			return res, err
+		}
+	}
+}
 
+// doAttempt sends a single attempt of req. The returned flag reports whether the request is worth
+// a retry: transport failures are, budget exhaustion is not. lastErr is the error of the previous
+// attempt, kept in the budget exhaustion error as it is the actual cause.
+func (cl *Client) doAttempt(ctx context.Context, req *nativehttp.Request, attempt, retriesLeft int, lastErr error) (*nativehttp.Response, bool, error) {
 	// This is a blocking call
-	err = cl.rl.Wait(ctx)
+	err := cl.rl.Wait(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "http: Client.Do cl.rl.Wait error")
+		// the caller gave up, like in ClassifyTransport cancellations are left untagged
+		if errors.Is(err, context.Canceled) || ctx.Err() == context.Canceled {
+			return nil, false, errors.Wrap(err, "http: Client.Do cl.rl.Wait error")
+		}
+		if _, ok := ctx.Deadline(); ok {
+			return nil, false, errors.Wrapf(ErrBudgetExhausted, "http: Client.Do cl.rl.Wait error: %v", err)
+		}
+		return nil, false, errors.WithKind(errors.Wrap(err, "http: Client.Do cl.rl.Wait error"), errors.RateLimited)
+	}
+
+	timeout, hasBudget := cl.attemptTimeout(ctx, retriesLeft)
+	if hasBudget && timeout <= 0 {
+		if lastErr != nil {
+			return nil, false, errors.Wrapf(ErrBudgetExhausted, "http: Client.Do endpoint[%s] attempt %d after: %v", req.URL.EscapedPath(), attempt+1, lastErr)
+		}
+		return nil, false, errors.Wrapf(ErrBudgetExhausted, "http: Client.Do endpoint[%s] attempt %d", req.URL.EscapedPath(), attempt+1)
+	}
+
+	attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+	if hasBudget {
+		attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	attemptReq := req.Clone(attemptCtx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, false, errors.Wrap(err, "http: Client.Do req.GetBody error")
+		}
+		attemptReq.Body = body
+	}
+	if hasBudget && cl.budgetHeader != "" {
+		attemptReq.Header.Set(cl.budgetHeader, strconv.FormatInt(timeout.Milliseconds(), 10))
 	}
-	res, err = cl.httpClient.Do(req)
+
+	res, err := cl.httpClient.Do(attemptReq)
 	if err != nil {
-		return nil, errors.Wrapf(err, "http: Client.Do endpoint[%s]", req.URL.EscapedPath())
+		cancel()
+
+		err = errors.ClassifyTransport(errors.Wrapf(err, "http: Client.Do endpoint[%s]", req.URL.EscapedPath()))
+		return nil, errors.IsRetryable(err), err
+	}
+
+	// the attempt context must outlive Do, so it's released once the body is closed
+	if res.Body != nil {
+		res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	} else {
+		cancel()
 	}
 
 	if cl.validate != nil {
-		res, err = cl.validate(res)
+		validated, err := cl.validate(res)
 		if err != nil {
-			// returned validation error
-			return nil, err
+			// returned validation error, retried only when the validator tagged it as retryable. The
+			// response is dropped, so its body is closed to release the connection.
+			if res.Body != nil {
+				_ = res.Body.Close()
+			}
+			cancel()
+			return nil, errors.IsRetryable(err), err
 		}
+		res = validated
+	}
+
+	return res, false, nil
+}
+
+// attemptTimeout returns the budget of the next attempt and whether the context has a deadline
+// at all. A non positive budget means the attempt can't be started.
+func (cl *Client) attemptTimeout(ctx context.Context, retriesLeft int) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+
+	remaining := time.Until(deadline)
+	timeout := remaining
+	if retriesLeft > 0 {
+		timeout -= cl.retryReserve
+	}
+
+	// when the reserve starves the attempt the whole budget is spent on it
+	if timeout < cl.minAttemptTimeout {
+		timeout = remaining
+	}
+	if timeout < cl.minAttemptTimeout {
+		return 0, true
 	}
 
-	return res, nil
+	return timeout, true
 }
 
 func (cl *Client) RegisterValidate(fn ValidatorFunc) {
 	cl.validate = fn
 }
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,263 @@
+package http
+
+import (
+	"context"
+	"io"
+	nativehttp "net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtavano/devkit/errors"
+	"github.com/mtavano/devkit/test"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHTTPClient struct {
+	delays  []time.Duration
+	calls   int
+	headers []nativehttp.Header
+}
+
+func (m *mockHTTPClient) Do(req *nativehttp.Request) (*nativehttp.Response, error) {
+	delay := m.delays[m.calls]
+	m.calls++
+	m.headers = append(m.headers, req.Header)
+
+	select {
+	case <-time.After(delay):
+		return test.CreateMockResponse("", nativehttp.StatusOK), nil
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+}
+
+func Test_Client_Do(t *testing.T) {
+	testCases := []struct {
+		name          string
+		opts          *Options
+		timeout       time.Duration
+		delays        []time.Duration
+		expectedCalls int
+		expectedErr   error
+		expectedCause string
+	}{
+		{
+			name:          "should do a single attempt without deadline",
+			opts:          &Options{MaxRequest: 100, WindowInSeconds: 1, MaxRetries: 2},
+			delays:        []time.Duration{0},
+			expectedCalls: 1,
+		},
+		{
+			name:          "should retry with the reserved budget",
+			opts:          &Options{MaxRequest: 100, WindowInSeconds: 1, MaxRetries: 1, RetryReserve: 150 * time.Millisecond},
+			timeout:       300 * time.Millisecond,
+			delays:        []time.Duration{time.Second, 0},
+			expectedCalls: 2,
+		},
+		{
+			name:          "should refuse an attempt that can't finish in time",
+			opts:          &Options{MaxRequest: 100, WindowInSeconds: 1, MinAttemptTimeout: time.Second},
+			timeout:       100 * time.Millisecond,
+			delays:        []time.Duration{0},
+			expectedCalls: 0,
+			expectedErr:   ErrBudgetExhausted,
+		},
+		{
+			name:          "should surface budget exhaustion after a failed attempt",
+			opts:          &Options{MaxRequest: 100, WindowInSeconds: 1, MaxRetries: 1, RetryReserve: 50 * time.Millisecond, MinAttemptTimeout: 100 * time.Millisecond},
+			timeout:       200 * time.Millisecond,
+			delays:        []time.Duration{time.Second, 0},
+			expectedCalls: 1,
+			expectedErr:   ErrBudgetExhausted,
+			expectedCause: context.DeadlineExceeded.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockHTTPClient{delays: tc.delays}
+			cl := NewClient(tc.opts, mock)
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			req, err := nativehttp.NewRequestWithContext(ctx, nativehttp.MethodGet, "http://localhost/resource", nil)
+			require.NoError(t, err)
+
+			res, err := cl.Do(req)
+			require.Equal(t, tc.expectedCalls, mock.calls)
+			if tc.expectedErr != nil {
+				require.Nil(t, res)
+				require.True(t, errors.Is(err, tc.expectedErr))
+				require.Contains(t, err.Error(), tc.expectedCause)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, nativehttp.StatusOK, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func Test_Client_Do_BudgetHeader(t *testing.T) {
+	mock := &mockHTTPClient{delays: []time.Duration{0}}
+	cl := NewClient(&Options{MaxRequest: 100, WindowInSeconds: 1, BudgetHeader: "X-Request-Budget"}, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	req, err := nativehttp.NewRequestWithContext(ctx, nativehttp.MethodGet, "http://localhost/resource", nil)
+	require.NoError(t, err)
+
+	res, err := cl.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	require.NotEmpty(t, mock.headers[0].Get("X-Request-Budget"))
+	require.Empty(t, req.Header.Get("X-Request-Budget"))
+}
+
+func Test_Client_Do_ErrorKinds(t *testing.T) {
+	mock := &mockHTTPClient{delays: []time.Duration{time.Second}}
+	cl := NewClient(&Options{MaxRequest: 100, WindowInSeconds: 1}, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := nativehttp.NewRequestWithContext(ctx, nativehttp.MethodGet, "http://localhost/resource", nil)
+	require.NoError(t, err)
+
+	_, err = cl.Do(req)
+	require.Error(t, err)
+	require.Equal(t, errors.Timeout, errors.KindOf(err))
+	require.True(t, errors.IsRetryable(err))
+}
+
+type failingHTTPClient struct {
+	bodies []string
+}
+
+func (m *failingHTTPClient) Do(req *nativehttp.Request) (*nativehttp.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	m.bodies = append(m.bodies, string(body))
+	return nil, errors.New("connection reset by peer")
+}
+
+func Test_Client_Do_BodyReplay(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           func() io.Reader
+		expectedBodies []string
+	}{
+		{
+			name:           "should retry bodies that can be replayed",
+			body:           func() io.Reader { return strings.NewReader("payload") },
+			expectedBodies: []string{"payload", "payload", "payload"},
+		},
+		{
+			name:           "should send once bodies that can't be replayed",
+			body:           func() io.Reader { return io.NopCloser(strings.NewReader("payload")) },
+			expectedBodies: []string{"payload"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &failingHTTPClient{}
+			cl := NewClient(&Options{MaxRequest: 100, WindowInSeconds: 1, MaxRetries: 2}, mock)
+
+			req, err := nativehttp.NewRequest(nativehttp.MethodPost, "http://localhost/resource", tc.body())
+			require.NoError(t, err)
+
+			_, err = cl.Do(req)
+			require.Error(t, err)
+			require.Equal(t, tc.expectedBodies, mock.bodies)
+		})
+	}
+}
+
+func Test_Client_Do_Canceled(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cancelAfter   time.Duration
+		expectedCalls int
+	}{
+		{
+			name:          "should leave a cancellation during the attempt untagged",
+			cancelAfter:   20 * time.Millisecond,
+			expectedCalls: 1,
+		},
+		{
+			name:          "should leave a cancellation during the limiter wait untagged",
+			expectedCalls: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockHTTPClient{delays: []time.Duration{time.Second, time.Second}}
+			cl := NewClient(&Options{MaxRequest: 100, WindowInSeconds: 1, MaxRetries: 1}, mock)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancelAfter > 0 {
+				time.AfterFunc(tc.cancelAfter, cancel)
+			} else {
+				cancel()
+			}
+
+			req, err := nativehttp.NewRequestWithContext(ctx, nativehttp.MethodGet, "http://localhost/resource", nil)
+			require.NoError(t, err)
+
+			_, err = cl.Do(req)
+			require.ErrorIs(t, err, context.Canceled)
+			require.Equal(t, errors.Unknown, errors.KindOf(err))
+			require.False(t, errors.IsRetryable(err))
+			require.Equal(t, tc.expectedCalls, mock.calls)
+		})
+	}
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (b *closeRecorder) Close() error {
+	b.closed = true
+	return nil
+}
+
+type bodiesHTTPClient struct {
+	bodies []*closeRecorder
+}
+
+func (m *bodiesHTTPClient) Do(req *nativehttp.Request) (*nativehttp.Response, error) {
+	body := &closeRecorder{Reader: strings.NewReader("{}")}
+	m.bodies = append(m.bodies, body)
+	return &nativehttp.Response{StatusCode: nativehttp.StatusServiceUnavailable, Body: body}, nil
+}
+
+func Test_Client_Do_ValidateCloses(t *testing.T) {
+	mock := &bodiesHTTPClient{}
+	cl := NewClient(&Options{MaxRequest: 100, WindowInSeconds: 1, MaxRetries: 1}, mock)
+	cl.RegisterValidate(func(res *nativehttp.Response) (*nativehttp.Response, error) {
+		return nil, errors.WithKind(errors.New("service unavailable"), errors.Unavailable)
+	})
+
+	req, err := nativehttp.NewRequest(nativehttp.MethodGet, "http://localhost/resource", nil)
+	require.NoError(t, err)
+
+	_, err = cl.Do(req)
+	require.Equal(t, errors.Unavailable, errors.KindOf(err))
+	require.Len(t, mock.bodies, 2)
+	for _, body := range mock.bodies {
+		require.True(t, body.closed)
+	}
+}
@@ -0,0 +1,183 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// aggregatorBuckets is the resolution of the sliding window
+	aggregatorBuckets = 60
+	// aggregatorMaxGroups bounds the memory used, the least recently seen group is evicted first
+	aggregatorMaxGroups = 1000
+	// defaultTopErrors is the number of groups served when no n is given
+	defaultTopErrors = 10
+)
+
+// ErrorGroup summarizes the occurrences of errors sharing a fingerprint.
+type ErrorGroup struct {
+	Fingerprint string `json:"fingerprint"`
+	Kind        Kind   `json:"kind"`
+	// Count is the number of occurrences inside the aggregator window
+	Count       int         `json:"count"`
+	FirstSeen   time.Time   `json:"first_seen"`
+	LastSeen    time.Time   `json:"last_seen"`
+	FirstSample *ErrorCause `json:"first_sample"`
+	LastSample  *ErrorCause `json:"last_sample"`
+
+	buckets []countBucket
+}
+
+type countBucket struct {
+	start time.Time
+	count int
+}
+
+// Aggregator counts errors per fingerprint over a sliding time window, keeping the first and
+// last occurrence of each group. It serves the top groups as JSON, to be mounted on a debug
+// endpoint.
+type Aggregator struct {
+	mu         sync.Mutex
+	window     time.Duration
+	bucketSize time.Duration
+	groups     map[string]*ErrorGroup
+
+	now func() time.Time
+}
+
+func NewAggregator(window time.Duration) *Aggregator {
+	bucketSize := window / aggregatorBuckets
+	if bucketSize <= 0 {
+		bucketSize = window
+	}
+
+	return &Aggregator{
+		window:     window,
+		bucketSize: bucketSize,
+		groups:     make(map[string]*ErrorGroup),
+		now:        time.Now,
+	}
+}
+
+// Record counts an occurrence of err and returns its fingerprint. first reports whether the
+// group wasn't seen inside the window, which is when an alert is worth sending.
+func (a *Aggregator) Record(err error) (fingerprint string, first bool) {
+	if err == nil {
+		return "", false
+	}
+
+	fingerprint = Fingerprint(err)
+	cause := GetCauseFromError(err)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	a.prune(now)
+
+	group, ok := a.groups[fingerprint]
+	if !ok {
+		if len(a.groups) >= aggregatorMaxGroups {
+			a.evictOldest()
+		}
+
+		group = &ErrorGroup{
+			Fingerprint: fingerprint,
+			Kind:        cause.Kind,
+			FirstSeen:   now,
+			FirstSample: cause,
+		}
+		a.groups[fingerprint] = group
+	}
+
+	group.LastSeen = now
+	group.LastSample = cause
+	group.Count++
+
+	start := now.Truncate(a.bucketSize)
+	if n := len(group.buckets); n > 0 && group.buckets[n-1].start.Equal(start) {
+		group.buckets[n-1].count++
+	} else {
+		group.buckets = append(group.buckets, countBucket{start: start, count: 1})
+	}
+
+	return fingerprint, !ok
+}
+
+// Top returns up to n groups with the most occurrences inside the window.
+func (a *Aggregator) Top(n int) []ErrorGroup {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.prune(a.now())
+
+	groups := make([]ErrorGroup, 0, len(a.groups))
+	for _, group := range a.groups {
+		snapshot := *group
+		snapshot.buckets = nil
+		groups = append(groups, snapshot)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].LastSeen.After(groups[j].LastSeen)
+	})
+
+	if n >= 0 && len(groups) > n {
+		groups = groups[:n]
+	}
+
+	return groups
+}
+
+// ServeHTTP renders the top groups as JSON. The n query parameter sets how many, 10 by default.
+func (a *Aggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := defaultTopErrors
+	if raw := r.URL.Query().Get("n"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			_ = WriteProblem(w, r, WithUserMessage(WithKind(New("errors: Aggregator.ServeHTTP invalid n"), Invalid), "n must be a positive number"))
+			return
+		}
+		n = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(a.Top(n))
+}
+
+// prune drops the buckets that left the window and the groups left without occurrences.
+func (a *Aggregator) prune(now time.Time) {
+	cutoff := now.Add(-a.window)
+	for fingerprint, group := range a.groups {
+		expired := 0
+		for expired < len(group.buckets) && !group.buckets[expired].start.Add(a.bucketSize).After(cutoff) {
+			group.Count -= group.buckets[expired].count
+			expired++
+		}
+		group.buckets = group.buckets[expired:]
+
+		if len(group.buckets) == 0 {
+			delete(a.groups, fingerprint)
+		}
+	}
+}
+
+func (a *Aggregator) evictOldest() {
+	var oldest *ErrorGroup
+	for _, group := range a.groups {
+		if oldest == nil || group.LastSeen.Before(oldest.LastSeen) {
+			oldest = group
+		}
+	}
+
+	if oldest != nil {
+		delete(a.groups, oldest.Fingerprint)
+	}
+}
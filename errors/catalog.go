@@ -0,0 +1,261 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/ansel1/merry"
+	v2 "github.com/ansel1/merry/v2"
+)
+
+const (
+	LocaleES = "es"
+	LocaleEN = "en"
+)
+
+// DefaultCatalog is the catalog used by WithCode, NewProblem and WriteProblem. Spanish is its
+// default locale, as most of our users are in Chile and Mexico.
+var DefaultCatalog = NewCatalog(LocaleES)
+
+type catalogEntry struct {
+	status    int
+	templates map[string]*template.Template
+}
+
+// Catalog holds user facing messages per error code and locale. Messages are text/template
+// templates executed with the params attached by WithCode.
+type Catalog struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	entries       map[string]*catalogEntry
+	locales       map[string]bool
+}
+
+func NewCatalog(defaultLocale string) *Catalog {
+	return &Catalog{
+		defaultLocale: normalizeLocale(defaultLocale),
+		entries:       make(map[string]*catalogEntry),
+		locales:       make(map[string]bool),
+	}
+}
+
+// DefaultLocale returns the locale used when the requested one has no message.
+func (c *Catalog) DefaultLocale() string {
+	return c.defaultLocale
+}
+
+// Register adds the messages of code, keyed by locale, replacing a previous registration. status
+// is the HTTP code attached by WithCode, zero keeps the one of the error.
+func (c *Catalog) Register(code string, status int, messages map[string]string) error {
+	entry := &catalogEntry{status: status, templates: make(map[string]*template.Template, len(messages))}
+	for locale, message := range messages {
+		tmpl, err := template.New(code).Option("missingkey=error").Parse(message)
+		if err != nil {
+			return WithKind(Wrapf(err, "errors: Catalog.Register code %q locale %q template.Parse error", code, locale), Invalid)
+		}
+		entry.templates[normalizeLocale(locale)] = tmpl
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[code] = entry
+	for locale := range entry.templates {
+		c.locales[locale] = true
+	}
+
+	return nil
+}
+
+// Status returns the HTTP code registered for code, or zero.
+func (c *Catalog) Status(code string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if entry, ok := c.entries[code]; ok {
+		return entry.status
+	}
+	return 0
+}
+
+// Message renders the message of code in locale. It falls back to the base language of locale,
+// es for es-CL, and then to the default locale.
+func (c *Catalog) Message(code, locale string, params map[string]interface{}) (string, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[code]
+	c.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	for _, candidate := range c.fallbacks(locale) {
+		tmpl, ok := entry.templates[candidate]
+		if !ok {
+			continue
+		}
+
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, params); err != nil {
+			return "", false
+		}
+		return sb.String(), true
+	}
+
+	return "", false
+}
+
+// Localize returns the user message of err in locale: the catalog message of its code when there
+// is one, otherwise UserMessage.
+func (c *Catalog) Localize(err error, locale string) string {
+	if code := CodeOf(err); code != "" {
+		if msg, ok := c.Message(code, locale, CodeParams(err)); ok {
+			return msg
+		}
+	}
+
+	return UserMessage(err)
+}
+
+// RequestLocale picks the locale of r: the one set with ContextWithLocale, otherwise the most
+// preferred Accept-Language the catalog has messages for, otherwise the default locale.
+func (c *Catalog) RequestLocale(r *http.Request) string {
+	if r == nil {
+		return c.defaultLocale
+	}
+	if locale := LocaleFromContext(r.Context()); locale != "" {
+		return normalizeLocale(locale)
+	}
+
+	return c.Locale(r.Header.Get("Accept-Language"))
+}
+
+// Locale picks the most preferred locale of an Accept-Language value the catalog has messages
+// for, otherwise the default locale.
+func (c *Catalog) Locale(acceptLanguage string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, locale := range parseAcceptLanguage(acceptLanguage) {
+		if c.locales[locale] {
+			return locale
+		}
+		if base := baseLocale(locale); c.locales[base] {
+			return base
+		}
+	}
+
+	return c.defaultLocale
+}
+
+func (c *Catalog) fallbacks(locale string) []string {
+	locale = normalizeLocale(locale)
+	return []string{locale, baseLocale(locale), c.defaultLocale}
+}
+
+type (
+	codeKey       struct{}
+	codeParamsKey struct{}
+)
+
+// the keys are rendered with these names among the ErrorCause values
+func (codeKey) String() string       { return "code" }
+func (codeParamsKey) String() string { return "code_params" }
+
+// WithCode attaches a catalog code and the params of its message. The HTTP code registered for it
+// in DefaultCatalog is attached too, an outer WithHTTPCode still overrides it.
+func WithCode(err error, code string, params map[string]interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	wrappers := []v2.Wrapper{v2.WithValue(codeKey{}, code), v2.WithValue(codeParamsKey{}, params)}
+	if status := DefaultCatalog.Status(code); status != 0 {
+		wrappers = append(wrappers, v2.WithHTTPCode(status))
+	}
+
+	return merry.WrapSkipping(err, 1, wrappers...)
+}
+
+// CodeOf returns the catalog code attached with WithCode, or an empty string.
+func CodeOf(err error) string {
+	code, _ := v2.Value(err, codeKey{}).(string)
+	return code
+}
+
+// CodeParams returns the message params attached with WithCode.
+func CodeParams(err error) map[string]interface{} {
+	params, _ := v2.Value(err, codeParamsKey{}).(map[string]interface{})
+	return params
+}
+
+type localeKey struct{}
+
+// ContextWithLocale sets the locale used to render errors, taking precedence over the
+// Accept-Language header.
+func ContextWithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale set with ContextWithLocale, or an empty string.
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// parseAcceptLanguage returns the locales of an Accept-Language header by preference.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	tags := []weighted{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := normalizeLocale(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		tags = append(tags, weighted{locale: locale, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	locales := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		locales = append(locales, tag.locale)
+	}
+	return locales
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func baseLocale(locale string) string {
+	if idx := strings.Index(locale, "-"); idx >= 0 {
+		return locale[:idx]
+	}
+	return locale
+}
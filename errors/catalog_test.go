@@ -0,0 +1,124 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Catalog_Message(t *testing.T) {
+	catalog := NewCatalog(LocaleES)
+	require.NoError(t, catalog.Register("insufficient_funds", http.StatusUnprocessableEntity, map[string]string{
+		LocaleES: "Saldo insuficiente en la cuenta {{.account}}",
+		LocaleEN: "Insufficient funds in account {{.account}}",
+	}))
+	require.NoError(t, catalog.Register("account_locked", http.StatusConflict, map[string]string{
+		LocaleES: "La cuenta está bloqueada",
+	}))
+
+	params := map[string]interface{}{"account": "acc_123"}
+
+	testCases := []struct {
+		name            string
+		code            string
+		locale          string
+		params          map[string]interface{}
+		expectedMessage string
+		expectedOK      bool
+	}{
+		{name: "should render the requested locale", code: "insufficient_funds", locale: "en", params: params, expectedMessage: "Insufficient funds in account acc_123", expectedOK: true},
+		{name: "should fall back to the base language", code: "insufficient_funds", locale: "es-CL", params: params, expectedMessage: "Saldo insuficiente en la cuenta acc_123", expectedOK: true},
+		{name: "should fall back to the default locale", code: "account_locked", locale: "en-US", expectedMessage: "La cuenta está bloqueada", expectedOK: true},
+		{name: "should fail on missing params", code: "insufficient_funds", locale: "en"},
+		{name: "should fail on unknown codes", code: "unknown", locale: "es"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, ok := catalog.Message(tc.code, tc.locale, tc.params)
+			require.Equal(t, tc.expectedOK, ok)
+			require.Equal(t, tc.expectedMessage, msg)
+		})
+	}
+
+	require.Error(t, catalog.Register("broken", 0, map[string]string{LocaleES: "{{.account"}))
+}
+
+func Test_Catalog_RequestLocale(t *testing.T) {
+	catalog := NewCatalog(LocaleES)
+	require.NoError(t, catalog.Register("account_locked", http.StatusConflict, map[string]string{
+		LocaleES: "La cuenta está bloqueada",
+		LocaleEN: "The account is locked",
+	}))
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		ctxLocale      string
+		expectedLocale string
+	}{
+		{name: "should default to spanish", expectedLocale: LocaleES},
+		{name: "should use the accepted language", acceptLanguage: "en-US,en;q=0.9", expectedLocale: LocaleEN},
+		{name: "should honour weights", acceptLanguage: "fr;q=0.9, en;q=0.5, es;q=0.8", expectedLocale: LocaleES},
+		{name: "should skip unsupported languages", acceptLanguage: "pt-BR", expectedLocale: LocaleES},
+		{name: "should prefer the context locale", acceptLanguage: "es", ctxLocale: "en", expectedLocale: LocaleEN},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+			if tc.ctxLocale != "" {
+				req = req.WithContext(ContextWithLocale(context.Background(), tc.ctxLocale))
+			}
+
+			require.Equal(t, tc.expectedLocale, catalog.RequestLocale(req))
+		})
+	}
+}
+
+func Test_WriteProblem_Localized(t *testing.T) {
+	require.NoError(t, DefaultCatalog.Register("test_movement_locked", http.StatusConflict, map[string]string{
+		LocaleES: "El movimiento {{.movement}} no se puede modificar",
+		LocaleEN: "The movement {{.movement}} can't be updated",
+	}))
+
+	err := Wrap(
+		WithCode(New("fintoc: movement mov_123 is locked"), "test_movement_locked", map[string]interface{}{"movement": "mov_123"}),
+		"service: Reconcile error",
+	)
+	require.Equal(t, "test_movement_locked", CodeOf(err))
+	require.Equal(t, http.StatusConflict, HTTPCode(err))
+	require.Equal(t, "test_movement_locked", GetCauseFromError(err).Values["code"])
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		expectedDetail string
+	}{
+		{name: "should render spanish by default", expectedDetail: "El movimiento mov_123 no se puede modificar"},
+		{name: "should render english on request", acceptLanguage: "en", expectedDetail: "The movement mov_123 can't be updated"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/movements/mov_123", nil)
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, WriteProblem(rec, req, err))
+			require.Equal(t, http.StatusConflict, rec.Code)
+
+			body := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Equal(t, tc.expectedDetail, body["detail"])
+			require.Equal(t, "test_movement_locked", body["code"])
+		})
+	}
+}
@@ -1,78 +1,212 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
-	"runtime"
+	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/ansel1/merry"
+	v2 "github.com/ansel1/merry/v2"
 )
 
+// The functions below skip their own frame when capturing the stack, so traces start at the
+// caller.
+
 func New(message string) error {
-	return merry.New(message)
+	return merry.WrapSkipping(stderrors.New(message), 1)
 }
 
 func Wrap(err error, message string) error {
-	return merry.Prepend(err, message)
+	return merry.WrapSkipping(err, 1, v2.PrependMessage(message))
 }
 
 func WithHTTPCode(err error, status int) merry.Error {
-	return merry.WithHTTPCode(err, status)
+	return merry.WrapSkipping(err, 1, v2.WithHTTPCode(status))
+}
+
+// HTTPCode returns the status code attached with WithHTTPCode. Errors without one get the code
+// of their kind, which is 500 for unclassified errors.
+func HTTPCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	if status, ok := v2.Lookup(err, merryHTTPCodeKey); ok {
+		if code, ok := status.(int); ok && code != 0 {
+			return code
+		}
+	}
+
+	return KindOf(err).HTTPCode()
 }
 
 func Wrapf(err error, format string, args ...interface{}) error {
-	return merry.Prependf(err, format, args...)
+	return merry.WrapSkipping(err, 1, v2.PrependMessagef(format, args...))
 }
 
 func Is(err error, origingals ...error) bool {
 	return merry.Is(err, origingals...)
 }
 
-type ErrorCause struct {
-	errMsg string
-	Values map[string]interface{}
-	Trace  []Frame
+// As finds the first error in err's chain that matches target, and if so, sets target to that
+// error value and returns true. It behaves exactly like the standard library errors.As.
+func As(err error, target interface{}) bool {
+	return stderrors.As(err, target)
+}
+
+// Unwrap returns the next error in err's chain, or nil if there is none.
+func Unwrap(err error) error {
+	return stderrors.Unwrap(err)
+}
+
+// Join returns an error that wraps the given errors, discarding nil values. It returns nil when
+// every error is nil. Is and As match any of the joined errors.
+func Join(errs ...error) error {
+	joined := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			joined = append(joined, err)
+		}
+	}
+
+	if len(joined) == 0 {
+		return nil
+	}
+
+	return &joinError{errs: joined}
+}
+
+// Errors returns the errors wrapped by err when it was built by Join or by any other error that
+// implements Unwrap() []error. Otherwise it returns err alone.
+func Errors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		return multi.Unwrap()
+	}
+
+	return []error{err}
+}
+
+type joinError struct {
+	errs []error
+}
+
+func (e *joinError) Error() string {
+	msgs := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
 }
 
-type Frame struct {
-	Function string `json:"function,omitempty"`
-	File     string `json:"file,omitempty"`
-	Line     int    `json:"line,omitempty"`
+func (e *joinError) Unwrap() []error {
+	return e.errs
+}
+
+type ErrorCause struct {
+	errMsg  string
+	cause   error
+	Kind    Kind
+	Values  map[string]interface{}
+	Trace   Trace
+	Wrapped *ErrorCause
 }
 
 func (ec *ErrorCause) Error() string {
 	return ec.errMsg
 }
 
+// Unwrap returns the error the cause was built from, so Is and As keep matching it.
+func (ec *ErrorCause) Unwrap() error {
+	return ec.cause
+}
+
+// GetCauseFromError extracts the details of err ready to be rendered. Messages and values go
+// through Redact and sensitive values stay hidden, the original error is still reachable with
+// Unwrap.
 func GetCauseFromError(err error) *ErrorCause {
-	return &ErrorCause{
-		errMsg: err.Error(),
+	if err == nil {
+		return nil
+	}
+
+	ec := &ErrorCause{
+		errMsg: Redact(err.Error()),
+		cause:  err,
+		Kind:   KindOf(err),
 		Values: makeValues(err),
 		Trace:  makeTrace(err),
 	}
+
+	// the wrapped chain only keeps what changes between levels: message and kind
+	parent := ec
+	for wrapped := nextWrapped(err); wrapped != nil; wrapped = nextWrapped(wrapped) {
+		parent.Wrapped = &ErrorCause{
+			errMsg: Redact(wrapped.Error()),
+			cause:  wrapped,
+			Kind:   KindOf(wrapped),
+		}
+		parent = parent.Wrapped
+	}
+
+	return ec
+}
+
+// nextWrapped returns the first error down err's chain with a different message, skipping the
+// layers that only attach values.
+func nextWrapped(err error) error {
+	msg := err.Error()
+	for wrapped := Unwrap(err); wrapped != nil; wrapped = Unwrap(wrapped) {
+		if wrapped.Error() != msg {
+			return wrapped
+		}
+	}
+
+	return nil
 }
 
 func makeValues(err error) map[string]interface{} {
 	values := make(map[string]interface{})
 	for k, v := range merry.Values(err) {
-		values[fmt.Sprintf("%v", k)] = v
+		if isInternalKey(k) {
+			continue
+		}
+		values[fmt.Sprintf("%v", k)] = redactValue(v)
+	}
+
+	// fields resolve their precedence across the whole chain
+	for k, v := range Fields(err) {
+		values[k] = redactValue(v)
 	}
 	return values
 }
 
-func makeTrace(err error) []Frame {
-	ff := []Frame{}
-	stackPointer := merry.Stack(err)
-	frames := runtime.CallersFrames(stackPointer)
-	for {
-		frame, more := frames.Next()
-		if !more {
-			break
-		}
-		ff = append(ff, Frame{
-			Function: frame.Function,
-			File:     frame.File,
-			Line:     frame.Line,
-		})
+// isInternalKey reports whether a value key is used by merry or this package to store data
+// exposed through dedicated fields, like the stack or the kind.
+func isInternalKey(key interface{}) bool {
+	switch key.(type) {
+	case nil:
+		return false
+	case kindKey:
+		return true
 	}
-	return ff
+
+	return reflect.TypeOf(key) == reflect.TypeOf(merryHTTPCodeKey)
 }
+
+// merryHTTPCodeKey is the key merry stores the HTTP code with. Its type is unexported and shared
+// by every key merry uses for its own values, so it is found on a probe error.
+var merryHTTPCodeKey = func() interface{} {
+	const probe = -1
+	for k, v := range v2.Values(v2.New("probe", v2.NoCaptureStack(), v2.WithHTTPCode(probe))) {
+		if v == probe {
+			return k
+		}
+	}
+	panic("errors: merry HTTP code key not found")
+}()
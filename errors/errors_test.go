@@ -0,0 +1,139 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	v2 "github.com/ansel1/merry/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d", e.status)
+}
+
+func Test_As(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "should find a foreign error wrapped by devkit",
+			err:            Wrap(&statusError{status: 404}, "fintoc: Client.GetAccounts error"),
+			expectedStatus: 404,
+		},
+		{
+			name:           "should find a foreign error across a stdlib wrap",
+			err:            fmt.Errorf("caller: %w", WithHTTPCode(Wrap(&statusError{status: 429}, "upstream"), 503)),
+			expectedStatus: 429,
+		},
+		{
+			name:           "should find a foreign error inside a joined error",
+			err:            Join(New("first"), Wrap(&statusError{status: 500}, "second")),
+			expectedStatus: 500,
+		},
+		{
+			name:           "should find a foreign error from its cause",
+			err:            GetCauseFromError(Wrap(&statusError{status: 401}, "fireblocks")),
+			expectedStatus: 401,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var target *statusError
+			require.True(t, As(tc.err, &target))
+			require.Equal(t, tc.expectedStatus, target.status)
+
+			target = nil
+			require.True(t, stderrors.As(tc.err, &target))
+			require.Equal(t, tc.expectedStatus, target.status)
+		})
+	}
+}
+
+func Test_Join(t *testing.T) {
+	errA := New("error a")
+	errB := stderrors.New("error b")
+
+	require.Nil(t, Join(nil, nil))
+
+	joined := Join(errA, nil, Wrap(errB, "wrapped"))
+	require.Equal(t, "error a\nwrapped: error b", joined.Error())
+	require.True(t, Is(joined, errA))
+	require.True(t, stderrors.Is(joined, errB))
+	require.Len(t, Errors(joined), 2)
+
+	wrapped := fmt.Errorf("outer: %w", joined)
+	require.True(t, stderrors.Is(wrapped, errA))
+	require.True(t, Is(wrapped, errB))
+	require.Equal(t, []error{errA}, Errors(errA))
+}
+
+func Test_GetCauseFromError_Unwrap(t *testing.T) {
+	sentinel := New("sentinel")
+	err := Wrap(sentinel, "fintoc: Client.GetAccounts error")
+
+	cause := GetCauseFromError(err)
+	require.Equal(t, err.Error(), cause.Error())
+	require.Equal(t, err, Unwrap(cause))
+	require.True(t, Is(cause, sentinel))
+	require.True(t, stderrors.Is(cause, sentinel))
+	require.Nil(t, GetCauseFromError(nil))
+}
+
+func Test_HTTPCode(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "should return the code of the kind",
+			err:            WithKind(New("account not found"), NotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "should prefer the code attached",
+			err:            WithHTTPCode(WithKind(New("account not found"), NotFound), http.StatusGone),
+			expectedStatus: http.StatusGone,
+		},
+		{
+			name:           "should prefer an internal server error attached",
+			err:            WithHTTPCode(WithKind(New("account not found"), NotFound), http.StatusInternalServerError),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "should default to internal server error",
+			err:            New("pq: connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "should return ok without error",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedStatus, HTTPCode(tc.err))
+		})
+	}
+}
+
+func Test_GetCauseFromError_Values(t *testing.T) {
+	err := v2.Wrap(WithHTTPCode(New("account not found"), http.StatusNotFound), v2.WithValue(nil, "no key"), v2.WithValue("fintoc_account_id", "acc_123"))
+
+	cause := GetCauseFromError(err)
+	require.Equal(t, "acc_123", cause.Values["fintoc_account_id"])
+	require.Equal(t, "no key", cause.Values["<nil>"])
+	require.NotContains(t, cause.Values, "http status code")
+	require.NotContains(t, cause.Values, "stack")
+}
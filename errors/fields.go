@@ -0,0 +1,84 @@
+package errors
+
+import (
+	"github.com/ansel1/merry"
+	v2 "github.com/ansel1/merry/v2"
+)
+
+type fieldKey string
+
+// WithField attaches a structured field to the error. Fields travel with the error through any
+// later wrap, so they don't need to be attached again by the callers.
+func WithField(err error, key string, value interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return merry.WrapSkipping(err, 1, v2.WithValue(fieldKey(key), value))
+}
+
+// Fields returns the fields attached along err's chain, including the ones of joined errors.
+// When a key was attached more than once the earliest value, the one closest to the root cause,
+// wins.
+func Fields(err error) map[string]interface{} {
+	fields := make(map[string]interface{})
+	collectFields(err, fields)
+	return fields
+}
+
+func collectFields(err error, fields map[string]interface{}) {
+	// walking from the outermost error down, deeper values overwrite the outer ones
+	for err != nil {
+		for k, v := range merry.Values(err) {
+			if key, ok := k.(fieldKey); ok {
+				fields[string(key)] = v
+			}
+		}
+
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			// between siblings the first joined error wins
+			merged := make(map[string]interface{})
+			for _, branch := range multi.Unwrap() {
+				branchFields := make(map[string]interface{})
+				collectFields(branch, branchFields)
+				for k, v := range branchFields {
+					if _, ok := merged[k]; !ok {
+						merged[k] = v
+					}
+				}
+			}
+
+			for k, v := range merged {
+				fields[k] = v
+			}
+			return
+		}
+
+		err = Unwrap(err)
+	}
+}
+
+// WithUserMessage attaches a message that is safe to show to customers. Unlike the error message
+// it never contains internal details.
+func WithUserMessage(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	return merry.WrapSkipping(err, 1, v2.WithUserMessage(msg))
+}
+
+// WithUserMessagef is WithUserMessage with a formatted message.
+func WithUserMessagef(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return merry.WrapSkipping(err, 1, v2.WithUserMessagef(format, args...))
+}
+
+// UserMessage returns the message attached with WithUserMessage, or an empty string. The
+// outermost message wins.
+func UserMessage(err error) string {
+	return merry.UserMessage(err)
+}
@@ -0,0 +1,69 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Fields(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedFields map[string]interface{}
+	}{
+		{
+			name:           "should return no fields for nil errors",
+			err:            nil,
+			expectedFields: map[string]interface{}{},
+		},
+		{
+			name:           "should ignore values that are not fields",
+			err:            WithKind(WithHTTPCode(New("some error"), 404), NotFound),
+			expectedFields: map[string]interface{}{},
+		},
+		{
+			name: "should merge fields across wraps",
+			err: WithField(
+				Wrap(WithField(New("account not found"), "fintoc_account_id", "acc_123"), "fintoc: Client.GetAccountMovements error"),
+				"operation", "reconcile",
+			),
+			expectedFields: map[string]interface{}{"fintoc_account_id": "acc_123", "operation": "reconcile"},
+		},
+		{
+			name: "should let the earliest value win",
+			err: WithField(
+				fmt.Errorf("caller: %w", WithField(New("vault not found"), "fireblocks_vault_id", "1")),
+				"fireblocks_vault_id", "2",
+			),
+			expectedFields: map[string]interface{}{"fireblocks_vault_id": "1"},
+		},
+		{
+			name: "should merge fields of joined errors",
+			err: WithField(Join(
+				WithField(New("first"), "fintoc_account_id", "acc_1"),
+				WithField(New("second"), "fireblocks_vault_id", "1"),
+				WithField(New("third"), "fintoc_account_id", "acc_3"),
+			), "operation", "reconcile"),
+			expectedFields: map[string]interface{}{"fintoc_account_id": "acc_1", "fireblocks_vault_id": "1", "operation": "reconcile"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedFields, Fields(tc.err))
+		})
+	}
+}
+
+func Test_UserMessage(t *testing.T) {
+	err := WithUserMessage(New("pq: duplicate key value violates unique constraint"), "The transfer already exists")
+	wrapped := Wrap(err, "payouts: Service.CreateTransfer error")
+
+	require.Equal(t, "The transfer already exists", UserMessage(wrapped))
+	require.Equal(t, "payouts: Service.CreateTransfer error: pq: duplicate key value violates unique constraint", wrapped.Error())
+	require.Equal(t, "Account 1 is locked", UserMessage(WithUserMessagef(wrapped, "Account %d is locked", 1)))
+	require.Empty(t, UserMessage(New("some error")))
+	require.Nil(t, WithUserMessage(nil, "ignored"))
+}
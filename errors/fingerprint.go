@@ -0,0 +1,73 @@
+package errors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// FingerprintDepth is the number of innermost frames taken into account by Fingerprint.
+const FingerprintDepth = 5
+
+var (
+	quotedPattern     = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	uuidPattern       = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	wordNumberPattern = regexp.MustCompile(`\b\d+\b`)
+	hexPattern        = regexp.MustCompile(`(?i)\b(?:0x)?[0-9a-f]*[0-9][0-9a-f]*\b`)
+	numberPattern     = regexp.MustCompile(`\d+`)
+)
+
+// Fingerprint returns a stable identifier of the kind of failure err represents, for grouping
+// occurrences of the same incident. It hashes the error kind, the root cause type and message
+// without its dynamic parts, and the functions of the innermost FingerprintDepth frames, so ids,
+// amounts and line numbers don't split groups.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "kind:%s\n", KindOf(err))
+
+	root := rootCause(err)
+	fmt.Fprintf(h, "root:%T:%s\n", root, normalizeMessage(root.Error()))
+
+	trace := makeTrace(err)
+	if len(trace) > FingerprintDepth {
+		trace = trace[:FingerprintDepth]
+	}
+	for _, frame := range trace {
+		fmt.Fprintf(h, "frame:%s\n", frame.Function)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// rootCause returns the innermost error of the chain, following the first error of joins.
+func rootCause(err error) error {
+	for {
+		var next error
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			if errs := multi.Unwrap(); len(errs) > 0 {
+				next = errs[0]
+			}
+		} else {
+			next = Unwrap(err)
+		}
+
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// normalizeMessage strips the parts of a message that change between occurrences.
+func normalizeMessage(msg string) string {
+	msg = quotedPattern.ReplaceAllString(msg, "<str>")
+	msg = uuidPattern.ReplaceAllString(msg, "<uuid>")
+	msg = wordNumberPattern.ReplaceAllString(msg, "<n>")
+	msg = hexPattern.ReplaceAllString(msg, "<id>")
+	return numberPattern.ReplaceAllString(msg, "<n>")
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func accountNotFound(accountID string) error {
+	return WithKind(New(fmt.Sprintf("account %q not found after 3 attempts", accountID)), NotFound)
+}
+
+func vaultNotFound(vaultID string) error {
+	return WithKind(New(fmt.Sprintf("vault %s not found", vaultID)), NotFound)
+}
+
+func Test_Fingerprint(t *testing.T) {
+	first := Fingerprint(Wrap(accountNotFound("acc_123"), "fintoc: Client.GetAccounts error"))
+	require.Len(t, first, 16)
+
+	// same site, different dynamic values
+	require.Equal(t, first, Fingerprint(Wrap(accountNotFound("acc_987"), "fintoc: Client.GetAccounts error")))
+
+	// same root, different site
+	require.NotEqual(t, first, Fingerprint(Wrap(vaultNotFound("7f3a"), "fintoc: Client.GetAccounts error")))
+
+	// same site, different kind
+	require.NotEqual(t, first, Fingerprint(WithKind(accountNotFound("acc_123"), Internal)))
+
+	require.Equal(t, "", Fingerprint(nil))
+}
+
+func Test_normalizeMessage(t *testing.T) {
+	require.Equal(t,
+		"vault <id> transfer <uuid> of <n> USDC failed: <str>",
+		normalizeMessage(`vault 7f3a9c transfer 3f2c1a4e-aaaa-bbbb-cccc-1234567890ab of 1500 USDC failed: "insufficient funds"`),
+	)
+}
+
+func Test_Aggregator(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	a := NewAggregator(time.Minute)
+	a.now = func() time.Time { return now }
+
+	fingerprint, first := a.Record(accountNotFound("acc_1"))
+	require.True(t, first)
+
+	now = now.Add(10 * time.Second)
+	again, first := a.Record(accountNotFound("acc_2"))
+	require.False(t, first)
+	require.Equal(t, fingerprint, again)
+
+	now = now.Add(10 * time.Second)
+	_, first = a.Record(vaultNotFound("1"))
+	require.True(t, first)
+
+	top := a.Top(10)
+	require.Len(t, top, 2)
+	require.Equal(t, fingerprint, top[0].Fingerprint)
+	require.Equal(t, 2, top[0].Count)
+	require.Equal(t, NotFound, top[0].Kind)
+	require.Equal(t, now.Add(-20*time.Second), top[0].FirstSeen)
+	require.Equal(t, now.Add(-10*time.Second), top[0].LastSeen)
+	require.Contains(t, top[0].FirstSample.Error(), "acc_1")
+	require.Contains(t, top[0].LastSample.Error(), "acc_2")
+	require.Equal(t, 1, top[1].Count)
+	require.Len(t, a.Top(1), 1)
+
+	// the first occurrence leaves the window
+	now = now.Add(45 * time.Second)
+	top = a.Top(10)
+	require.Len(t, top, 2)
+	require.Equal(t, 1, top[0].Count)
+
+	now = now.Add(time.Minute)
+	require.Empty(t, a.Top(10))
+	_, first = a.Record(accountNotFound("acc_3"))
+	require.True(t, first)
+}
+
+func Test_Aggregator_ServeHTTP(t *testing.T) {
+	a := NewAggregator(time.Minute)
+	a.Record(accountNotFound("acc_1"))
+	a.Record(vaultNotFound("1"))
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errors?n=1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var groups []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &groups))
+	require.Len(t, groups, 1)
+	require.Equal(t, "not_found", groups[0]["kind"])
+
+	rec = httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errors?n=all", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,250 @@
+// Package grpcstatus maps devkit errors to gRPC statuses and back, carrying the kind, fields, user
+// message and retry hints as rich error details.
+package grpcstatus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	v2 "github.com/ansel1/merry/v2"
+	"github.com/mtavano/devkit/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// RetryDelayField is the field read as the RetryInfo delay, it must hold a time.Duration
+	RetryDelayField = "retry_delay"
+
+	httpCodeMetadata = "http_code"
+)
+
+// Domain is the ErrorInfo domain of the statuses built by ToStatus.
+var Domain = "devkit"
+
+var kindCodes = map[errors.Kind]codes.Code{
+	errors.Unknown:      codes.Unknown,
+	errors.NotFound:     codes.NotFound,
+	errors.Unauthorized: codes.Unauthenticated,
+	errors.Forbidden:    codes.PermissionDenied,
+	errors.Invalid:      codes.InvalidArgument,
+	errors.Conflict:     codes.AlreadyExists,
+	errors.RateLimited:  codes.ResourceExhausted,
+	errors.Unavailable:  codes.Unavailable,
+	errors.Timeout:      codes.DeadlineExceeded,
+	errors.Internal:     codes.Internal,
+}
+
+var codeKinds = map[codes.Code]errors.Kind{
+	codes.NotFound:           errors.NotFound,
+	codes.Unauthenticated:    errors.Unauthorized,
+	codes.PermissionDenied:   errors.Forbidden,
+	codes.InvalidArgument:    errors.Invalid,
+	codes.FailedPrecondition: errors.Invalid,
+	codes.OutOfRange:         errors.Invalid,
+	codes.AlreadyExists:      errors.Conflict,
+	codes.Aborted:            errors.Conflict,
+	codes.ResourceExhausted:  errors.RateLimited,
+	codes.Unavailable:        errors.Unavailable,
+	codes.DeadlineExceeded:   errors.Timeout,
+	codes.Internal:           errors.Internal,
+	codes.DataLoss:           errors.Internal,
+}
+
+// CodeOf returns the gRPC code of a kind.
+func CodeOf(kind errors.Kind) codes.Code {
+	if code, ok := kindCodes[kind]; ok {
+		return code
+	}
+	return codes.Unknown
+}
+
+// KindOf returns the kind of a gRPC code, Unknown for the codes without one.
+func KindOf(code codes.Code) errors.Kind {
+	return codeKinds[code]
+}
+
+type violationsKey struct{}
+
+func (violationsKey) String() string { return "field_violations" }
+
+// WithFieldViolation attaches a field violation rendered in the BadRequest detail.
+func WithFieldViolation(err error, field, description string) error {
+	if err == nil {
+		return nil
+	}
+
+	violations := append(fieldViolations(err), &errdetails.BadRequest_FieldViolation{Field: field, Description: description})
+	return v2.WrapSkipping(err, 1, v2.WithValue(violationsKey{}, violations))
+}
+
+func fieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	violations, _ := v2.Value(err, violationsKey{}).([]*errdetails.BadRequest_FieldViolation)
+	return append([]*errdetails.BadRequest_FieldViolation{}, violations...)
+}
+
+type publicFieldsKey struct{}
+
+func (publicFieldsKey) String() string { return "public_fields" }
+
+// WithPublicField attaches a field that ToStatus sends to the client in the ErrorInfo metadata.
+// Fields attached with errors.WithField stay in the service.
+func WithPublicField(err error, key string, value interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	keys := append(publicFields(err), key)
+	return v2.WrapSkipping(errors.WithField(err, key, value), 1, v2.WithValue(publicFieldsKey{}, keys))
+}
+
+func publicFields(err error) []string {
+	keys, _ := v2.Value(err, publicFieldsKey{}).([]string)
+	return append([]string{}, keys...)
+}
+
+// ToStatus converts err into a status with the default locale of errors.DefaultCatalog.
+func ToStatus(err error) *status.Status {
+	return ToLocalizedStatus(err, errors.DefaultCatalog.DefaultLocale())
+}
+
+// ToLocalizedStatus converts err into a status. Like problem responses the message is the user
+// message, in locale, or the generic text of the status, and only the fields attached with
+// WithPublicField are sent, so internal details never leave the service. Errors carrying a status,
+// even wrapped, are returned as that status and cancellations as Canceled.
+func ToLocalizedStatus(err error, locale string) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	var withStatus interface {
+		error
+		GRPCStatus() *status.Status
+	}
+	if errors.As(err, &withStatus) {
+		st, _ := status.FromError(withStatus)
+		return st
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.New(codes.Canceled, context.Canceled.Error())
+	}
+
+	kind := errors.KindOf(err)
+	httpCode := errors.HTTPCode(err)
+	if kind == errors.Unknown {
+		kind = errors.KindFromHTTPCode(httpCode)
+	}
+
+	userMessage := errors.Redact(errors.DefaultCatalog.Localize(err, locale))
+	message := userMessage
+	if message == "" {
+		message = http.StatusText(httpCode)
+	}
+
+	reason := errors.CodeOf(err)
+	if reason == "" {
+		reason = strings.ToUpper(kind.String())
+	}
+
+	fields := errors.Fields(err)
+	metadata := map[string]string{httpCodeMetadata: strconv.Itoa(httpCode)}
+	for _, k := range publicFields(err) {
+		v, ok := fields[k]
+		if !ok || k == RetryDelayField || k == errors.PanicField {
+			continue
+		}
+		metadata[k] = errors.Redact(fmt.Sprint(v))
+	}
+
+	st := status.New(CodeOf(kind), message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: Domain, Metadata: metadata}}
+	if kind.Retryable() {
+		info := &errdetails.RetryInfo{}
+		if delay, ok := fields[RetryDelayField].(time.Duration); ok {
+			info.RetryDelay = durationpb.New(delay)
+		}
+		details = append(details, info)
+	}
+	violations := fieldViolations(err)
+	if validation, ok := errors.ValidationErrorsOf(err); ok {
+		for _, fe := range validation {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: errors.Redact(fe.Message)})
+		}
+	}
+	if len(violations) > 0 {
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if userMessage != "" {
+		details = append(details, &errdetails.LocalizedMessage{Locale: locale, Message: userMessage})
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// FromStatus converts a status received from a gRPC call into a devkit error, restoring the kind,
+// HTTP code, public fields, user message and field violations sent by ToStatus.
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	kind := KindOf(st.Code())
+	err := errors.WithKind(errors.New(st.Message()), kind)
+	userMessage := ""
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.Reason != "" && d.Reason != strings.ToUpper(kind.String()) {
+				err = errors.WithCode(err, d.Reason, nil)
+			}
+			// the status sent wins over the one registered for the code
+			for k, v := range d.Metadata {
+				if k == httpCodeMetadata {
+					if code, convErr := strconv.Atoi(v); convErr == nil {
+						err = errors.WithHTTPCode(err, code)
+					}
+					continue
+				}
+				err = errors.WithField(err, k, v)
+			}
+		case *errdetails.RetryInfo:
+			if d.RetryDelay != nil {
+				err = errors.WithField(err, RetryDelayField, d.RetryDelay.AsDuration())
+			}
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				err = WithFieldViolation(err, v.Field, v.Description)
+			}
+		case *errdetails.LocalizedMessage:
+			userMessage = d.Message
+		}
+	}
+
+	if userMessage != "" {
+		err = errors.WithUserMessage(err, userMessage)
+	}
+
+	return err
+}
+
+// FromError converts the error returned by a gRPC client call, errors without a status are
+// returned as they are.
+func FromError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	return FromStatus(st)
+}
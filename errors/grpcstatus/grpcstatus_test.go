@@ -0,0 +1,185 @@
+package grpcstatus
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mtavano/devkit/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func Test_ToStatus(t *testing.T) {
+	testCases := []struct {
+		name            string
+		err             error
+		expectedCode    codes.Code
+		expectedMessage string
+		expectedReason  string
+		expectedRetry   bool
+	}{
+		{
+			name:            "should hide internal messages",
+			err:             errors.New("pq: connection refused to 10.0.0.1"),
+			expectedCode:    codes.Internal,
+			expectedMessage: "Internal Server Error",
+			expectedReason:  "INTERNAL",
+		},
+		{
+			name:            "should map the kind and the user message",
+			err:             errors.WithUserMessage(errors.WithKind(errors.New("account acc_123 not found"), errors.NotFound), "The account doesn't exist"),
+			expectedCode:    codes.NotFound,
+			expectedMessage: "The account doesn't exist",
+			expectedReason:  "NOT_FOUND",
+		},
+		{
+			name:            "should map the http code when there is no kind",
+			err:             errors.WithHTTPCode(errors.New("fintoc: too many requests"), http.StatusTooManyRequests),
+			expectedCode:    codes.ResourceExhausted,
+			expectedMessage: "Too Many Requests",
+			expectedReason:  "RATE_LIMITED",
+			expectedRetry:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := ToStatus(tc.err)
+			require.Equal(t, tc.expectedCode, st.Code())
+			require.Equal(t, tc.expectedMessage, st.Message())
+
+			var info *errdetails.ErrorInfo
+			retry := false
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.ErrorInfo:
+					info = d
+				case *errdetails.RetryInfo:
+					retry = true
+				}
+			}
+			require.NotNil(t, info)
+			require.Equal(t, tc.expectedReason, info.Reason)
+			require.Equal(t, Domain, info.Domain)
+			require.Equal(t, tc.expectedRetry, retry)
+		})
+	}
+
+	require.Equal(t, codes.OK, ToStatus(nil).Code())
+	require.Equal(t, codes.Aborted, ToStatus(status.Error(codes.Aborted, "aborted")).Code())
+	require.Equal(t, codes.Canceled, ToStatus(errors.Wrap(context.Canceled, "fintoc: get accounts")).Code())
+
+	wrapped := ToStatus(errors.Wrap(status.Error(codes.NotFound, "account not found"), "fintoc: get account"))
+	require.Equal(t, codes.NotFound, wrapped.Code())
+	require.Equal(t, "account not found", wrapped.Message())
+}
+
+func Test_FromStatus(t *testing.T) {
+	err := errors.WithKind(errors.New("fireblocks: vault 7 is busy"), errors.Unavailable)
+	err = WithPublicField(err, "fireblocks_vault_id", "7")
+	err = WithPublicField(err, "api_key", errors.Sensitive("sk_live_123"))
+	err = errors.WithField(err, "fintoc_account_id", "acc_123")
+	err = errors.WithField(err, RetryDelayField, 2*time.Second)
+	err = errors.WithUserMessage(err, "Try again in a few seconds")
+	err = WithFieldViolation(err, "/amount", "must be positive")
+
+	got := FromError(ToStatus(err).Err())
+	require.Equal(t, errors.Unavailable, errors.KindOf(got))
+	require.Equal(t, http.StatusServiceUnavailable, errors.HTTPCode(got))
+	require.Equal(t, "Try again in a few seconds", errors.UserMessage(got))
+
+	fields := errors.Fields(got)
+	require.Equal(t, "7", fields["fireblocks_vault_id"])
+	require.Equal(t, errors.Redacted, fields["api_key"])
+	require.NotContains(t, fields, "fintoc_account_id")
+	require.Equal(t, 2*time.Second, fields[RetryDelayField])
+
+	violations := fieldViolations(got)
+	require.Len(t, violations, 1)
+	require.Equal(t, "/amount", violations[0].Field)
+
+	require.NoError(t, FromStatus(status.New(codes.OK, "")))
+	plain := errors.New("not a status")
+	require.Equal(t, plain, FromError(plain))
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func Test_ToStatus_Panics(t *testing.T) {
+	err := errors.Safe(func() error {
+		var balances map[string]int
+		balances["vault 7"] = 10
+		return nil
+	})
+	err = WithPublicField(err, errors.PanicField, "leaked")
+
+	for _, detail := range ToStatus(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			require.NotContains(t, info.Metadata, errors.PanicField)
+		}
+	}
+}
+
+func Test_Interceptors(t *testing.T) {
+	require.NoError(t, errors.DefaultCatalog.Register("grpc_test_vault_busy", http.StatusServiceUnavailable, map[string]string{
+		errors.LocaleES: "La bóveda está ocupada",
+		errors.LocaleEN: "The vault is busy",
+	}))
+
+	handlerErr := errors.WithCode(errors.New("fireblocks: vault 7 is busy"), "grpc_test_vault_busy", nil)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "en-US"))
+
+	_, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, handlerErr
+	})
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.Unavailable, st.Code())
+	require.Equal(t, "The vault is busy", st.Message())
+
+	err = StreamServerInterceptor()(nil, &serverStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		return handlerErr
+	})
+	st, ok = status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, "La bóveda está ocupada", st.Message())
+	require.Equal(t, "grpc_test_vault_busy", errors.CodeOf(FromStatus(st)))
+
+	res, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ok", res)
+}
+
+func Test_ToStatus_ValidationErrors(t *testing.T) {
+	var errs errors.ValidationErrors
+	errs.Add(errors.FieldPath("max_items"), "max", "must be at most 300")
+
+	st := ToStatus(errs.Err())
+	require.Equal(t, codes.InvalidArgument, st.Code())
+
+	var badRequest *errdetails.BadRequest
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = d
+		}
+	}
+	require.NotNil(t, badRequest)
+	require.Len(t, badRequest.FieldViolations, 1)
+	require.Equal(t, "/max_items", badRequest.FieldViolations[0].Field)
+	require.Equal(t, "must be at most 300", badRequest.FieldViolations[0].Description)
+}
@@ -0,0 +1,52 @@
+package grpcstatus
+
+import (
+	"context"
+
+	"github.com/mtavano/devkit/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor converts the errors returned by unary handlers into statuses, with the
+// user message in the locale of the accept-language metadata.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		res, err := handler(ctx, req)
+		if err != nil {
+			return res, ToLocalizedStatus(err, contextLocale(ctx)).Err()
+		}
+
+		return res, nil
+	}
+}
+
+// StreamServerInterceptor converts the errors returned by stream handlers into statuses, with
+// the user message in the locale of the accept-language metadata.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			return ToLocalizedStatus(err, contextLocale(ss.Context())).Err()
+		}
+
+		return nil
+	}
+}
+
+// contextLocale picks the locale set with errors.ContextWithLocale, or the one of the
+// accept-language metadata.
+func contextLocale(ctx context.Context) string {
+	if locale := errors.LocaleFromContext(ctx); locale != "" {
+		return locale
+	}
+
+	acceptLanguage := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("accept-language"); len(values) > 0 {
+			acceptLanguage = values[0]
+		}
+	}
+
+	return errors.DefaultCatalog.Locale(acceptLanguage)
+}
@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type errorCauseJSON struct {
+	Message string                 `json:"message"`
+	Kind    Kind                   `json:"kind,omitempty"`
+	Values  map[string]interface{} `json:"values,omitempty"`
+	Trace   []Frame                `json:"trace,omitempty"`
+	Wrapped *ErrorCause            `json:"wrapped,omitempty"`
+}
+
+// MarshalJSON encodes the cause with its message, kind, values, trace and wrapped chain. Values
+// that can't be encoded as JSON are replaced by their %v representation.
+func (ec *ErrorCause) MarshalJSON() ([]byte, error) {
+	var values map[string]interface{}
+	if len(ec.Values) > 0 {
+		values = make(map[string]interface{}, len(ec.Values))
+		for k, v := range ec.Values {
+			if _, err := json.Marshal(v); err != nil {
+				v = fmt.Sprintf("%v", v)
+			}
+			values[k] = v
+		}
+	}
+
+	return json.Marshal(&errorCauseJSON{
+		Message: ec.errMsg,
+		Kind:    ec.Kind,
+		Values:  values,
+		Trace:   ec.Trace,
+		Wrapped: ec.Wrapped,
+	})
+}
+
+// UnmarshalJSON decodes a cause encoded by MarshalJSON. The decoded cause unwraps to its wrapped
+// chain, so Is and As can still walk it, although the original error values are lost.
+func (ec *ErrorCause) UnmarshalJSON(data []byte) error {
+	var decoded errorCauseJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return Wrap(err, "errors: ErrorCause.UnmarshalJSON json.Unmarshal error")
+	}
+
+	*ec = ErrorCause{
+		errMsg:  decoded.Message,
+		Kind:    decoded.Kind,
+		Values:  decoded.Values,
+		Trace:   decoded.Trace,
+		Wrapped: decoded.Wrapped,
+	}
+	if decoded.Wrapped != nil {
+		ec.cause = decoded.Wrapped
+	}
+
+	return nil
+}
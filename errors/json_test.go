@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ErrorCause_JSON(t *testing.T) {
+	sentinel := WithKind(New("account not found"), NotFound)
+	err := WithField(Wrap(sentinel, "fintoc: Client.GetAccounts error"), "account_type", "checking_account")
+
+	cause := GetCauseFromError(err)
+	require.Equal(t, NotFound, cause.Kind)
+	require.Equal(t, map[string]interface{}{"account_type": "checking_account"}, cause.Values)
+	require.NotEmpty(t, cause.Trace)
+	require.NotNil(t, cause.Wrapped)
+	require.Equal(t, "account not found", cause.Wrapped.Error())
+	require.Nil(t, cause.Wrapped.Wrapped)
+
+	data, err := json.Marshal(cause)
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &body))
+	require.Equal(t, "fintoc: Client.GetAccounts error: account not found", body["message"])
+	require.Equal(t, "not_found", body["kind"])
+	require.Equal(t, map[string]interface{}{"message": "account not found", "kind": "not_found"}, body["wrapped"])
+
+	var decoded ErrorCause
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, cause.Error(), decoded.Error())
+	require.Equal(t, NotFound, KindOf(&decoded))
+	require.Equal(t, cause.Values, decoded.Values)
+	require.Equal(t, cause.Trace, decoded.Trace)
+	require.Equal(t, "account not found", Unwrap(&decoded).Error())
+}
+
+func Test_ErrorCause_JSON_UnsupportedValues(t *testing.T) {
+	err := WithField(New("some error"), "callback", func() {})
+
+	data, err := json.Marshal(GetCauseFromError(err))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"callback":"0x`)
+}
@@ -0,0 +1,206 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"net/http"
+
+	"github.com/ansel1/merry"
+	v2 "github.com/ansel1/merry/v2"
+)
+
+// Kind classifies an error so callers can react to it without matching its message.
+type Kind int
+
+const (
+	// Unknown is the kind of errors that were never classified
+	Unknown Kind = iota
+	// NotFound means the requested resource does not exist
+	NotFound
+	// Unauthorized means the caller is not authenticated
+	Unauthorized
+	// Forbidden means the caller is authenticated but not allowed
+	Forbidden
+	// Invalid means the request or its input is malformed
+	Invalid
+	// Conflict means the request collides with the current state of the resource
+	Conflict
+	// RateLimited means the caller exceeded a quota
+	RateLimited
+	// Unavailable means the dependency could not be reached or is temporarily down
+	Unavailable
+	// Timeout means the operation ran out of time
+	Timeout
+	// Internal means a bug or an unexpected condition
+	Internal
+)
+
+var kindNames = map[Kind]string{
+	Unknown:      "unknown",
+	NotFound:     "not_found",
+	Unauthorized: "unauthorized",
+	Forbidden:    "forbidden",
+	Invalid:      "invalid",
+	Conflict:     "conflict",
+	RateLimited:  "rate_limited",
+	Unavailable:  "unavailable",
+	Timeout:      "timeout",
+	Internal:     "internal",
+}
+
+var kindHTTPCodes = map[Kind]int{
+	Unknown:      http.StatusInternalServerError,
+	NotFound:     http.StatusNotFound,
+	Unauthorized: http.StatusUnauthorized,
+	Forbidden:    http.StatusForbidden,
+	Invalid:      http.StatusBadRequest,
+	Conflict:     http.StatusConflict,
+	RateLimited:  http.StatusTooManyRequests,
+	Unavailable:  http.StatusServiceUnavailable,
+	Timeout:      http.StatusGatewayTimeout,
+	Internal:     http.StatusInternalServerError,
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+
+	return kindNames[Unknown]
+}
+
+// MarshalText encodes the kind as its name.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a kind name. Unknown names decode to Unknown.
+func (k *Kind) UnmarshalText(text []byte) error {
+	*k = Unknown
+	for kind, name := range kindNames {
+		if name == string(text) {
+			*k = kind
+			break
+		}
+	}
+
+	return nil
+}
+
+// HTTPCode returns the status code that best represents the kind.
+func (k Kind) HTTPCode() int {
+	if code, ok := kindHTTPCodes[k]; ok {
+		return code
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Retryable reports whether an operation failing with this kind may succeed if attempted again.
+func (k Kind) Retryable() bool {
+	switch k {
+	case RateLimited, Unavailable, Timeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// KindFromHTTPCode classifies an upstream response status.
+func KindFromHTTPCode(status int) Kind {
+	switch status {
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return Invalid
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return Timeout
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return Unavailable
+	case http.StatusInternalServerError:
+		return Internal
+	}
+
+	switch {
+	case status >= 500:
+		return Unavailable
+	case status >= 400:
+		return Invalid
+	default:
+		return Unknown
+	}
+}
+
+type kindKey struct{}
+
+// WithKind attaches a kind to the error. The outermost kind wins.
+func WithKind(err error, kind Kind) error {
+	if err == nil {
+		return nil
+	}
+
+	return merry.WrapSkipping(err, 1, v2.WithValue(kindKey{}, kind))
+}
+
+// KindOf returns the kind attached to the error. Errors without a kind are classified from
+// well known causes such as context deadlines and network timeouts, otherwise Unknown.
+func KindOf(err error) Kind {
+	if err == nil {
+		return Unknown
+	}
+
+	if kind, ok := merry.Value(err, kindKey{}).(Kind); ok {
+		return kind
+	}
+
+	// causes decoded from JSON carry their kind without the original error
+	var cause *ErrorCause
+	if stderrors.As(err, &cause) && cause.Kind != Unknown {
+		return cause.Kind
+	}
+
+	var validation ValidationErrors
+	if stderrors.As(err, &validation) {
+		return Invalid
+	}
+
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		return Timeout
+	}
+
+	var netErr net.Error
+	if stderrors.As(err, &netErr) && netErr.Timeout() {
+		return Timeout
+	}
+
+	return Unknown
+}
+
+// IsRetryable reports whether the operation that returned err may succeed if attempted again.
+func IsRetryable(err error) bool {
+	return KindOf(err).Retryable()
+}
+
+// ClassifyTransport tags the errors of a transport, such as the one of an http client, that carry
+// no kind as Unavailable. Timeouts already are of the Timeout kind. Cancellations are left untagged,
+// the caller gave up so they are not worth a retry.
+func ClassifyTransport(err error) error {
+	if err == nil || stderrors.Is(err, context.Canceled) {
+		return err
+	}
+
+	if KindOf(err) != Unknown {
+		return err
+	}
+
+	return WithKind(err, Unavailable)
+}
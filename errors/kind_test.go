@@ -0,0 +1,118 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KindOf(t *testing.T) {
+	testCases := []struct {
+		name              string
+		err               error
+		expectedKind      Kind
+		expectedRetryable bool
+	}{
+		{
+			name:         "should return unknown for nil errors",
+			err:          nil,
+			expectedKind: Unknown,
+		},
+		{
+			name:         "should return unknown for unclassified errors",
+			err:          New("some error"),
+			expectedKind: Unknown,
+		},
+		{
+			name:         "should return the attached kind",
+			err:          WithKind(New("account not found"), NotFound),
+			expectedKind: NotFound,
+		},
+		{
+			name:              "should keep the kind across wraps",
+			err:               Wrap(WithKind(New("too many requests"), RateLimited), "fintoc: Client.GetAccounts error"),
+			expectedKind:      RateLimited,
+			expectedRetryable: true,
+		},
+		{
+			name:              "should keep the kind across foreign wraps",
+			err:               fmt.Errorf("caller: %w", WithKind(New("down"), Unavailable)),
+			expectedKind:      Unavailable,
+			expectedRetryable: true,
+		},
+		{
+			name:         "should let the outermost kind win",
+			err:          WithKind(WithKind(New("bad request"), Invalid), Internal),
+			expectedKind: Internal,
+		},
+		{
+			name:              "should classify context deadlines as timeouts",
+			err:               Wrap(context.DeadlineExceeded, "http: Client.Do error"),
+			expectedKind:      Timeout,
+			expectedRetryable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedKind, KindOf(tc.err))
+			require.Equal(t, tc.expectedRetryable, IsRetryable(tc.err))
+		})
+	}
+}
+
+func Test_KindFromHTTPCode(t *testing.T) {
+	require.Equal(t, NotFound, KindFromHTTPCode(http.StatusNotFound))
+	require.Equal(t, Invalid, KindFromHTTPCode(http.StatusUnprocessableEntity))
+	require.Equal(t, RateLimited, KindFromHTTPCode(http.StatusTooManyRequests))
+	require.Equal(t, Unavailable, KindFromHTTPCode(http.StatusBadGateway))
+	require.Equal(t, Unavailable, KindFromHTTPCode(599))
+	require.Equal(t, Internal, KindFromHTTPCode(http.StatusInternalServerError))
+	require.Equal(t, Unknown, KindFromHTTPCode(http.StatusOK))
+}
+
+func Test_ClassifyTransport(t *testing.T) {
+	testCases := []struct {
+		name              string
+		err               error
+		expectedKind      Kind
+		expectedRetryable bool
+	}{
+		{
+			name:              "should tag transport errors as unavailable",
+			err:               New("connection reset by peer"),
+			expectedKind:      Unavailable,
+			expectedRetryable: true,
+		},
+		{
+			name:              "should keep timeouts",
+			err:               Wrap(context.DeadlineExceeded, "http: Client.Do error"),
+			expectedKind:      Timeout,
+			expectedRetryable: true,
+		},
+		{
+			name:              "should keep the kind already set",
+			err:               WithKind(New("too many requests"), RateLimited),
+			expectedKind:      RateLimited,
+			expectedRetryable: true,
+		},
+		{
+			name:         "should leave cancellations untagged",
+			err:          Wrap(context.Canceled, "http: Client.Do error"),
+			expectedKind: Unknown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ClassifyTransport(tc.err)
+			require.Equal(t, tc.expectedKind, KindOf(err))
+			require.Equal(t, tc.expectedRetryable, IsRetryable(err))
+		})
+	}
+
+	require.NoError(t, ClassifyTransport(nil))
+}
@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/ansel1/merry"
+	v2 "github.com/ansel1/merry/v2"
+)
+
+// PanicField is the field holding the value a recovered panic was raised with.
+const PanicField = "panic"
+
+// FromPanic converts the value returned by recover into an Internal error with the value in the
+// PanicField field. Error values are wrapped, so Is and As still match them. It must be called
+// while the panic is being handled, from the deferred function, so the captured stack starts at
+// the panic site, also in TraceCaller mode.
+func FromPanic(recovered interface{}) error {
+	if recovered == nil {
+		return nil
+	}
+
+	var err error
+	if e, ok := recovered.(error); ok {
+		err = fmt.Errorf("panic: %w", e)
+	} else {
+		err = fmt.Errorf("panic: %v", recovered)
+	}
+
+	wrappers := []v2.Wrapper{
+		v2.WithValue(kindKey{}, Internal),
+		v2.WithValue(fieldKey(PanicField), recovered),
+	}
+	// replace any stack the value carried with the one of the panic
+	if v2.StackCaptureEnabled() {
+		wrappers = append(wrappers, v2.WithStack(panicStack()))
+	}
+
+	return merry.WrapSkipping(err, 1, wrappers...)
+}
+
+// panicStackBuffer is the number of frames looked at to find the panic site.
+const panicStackBuffer = 100
+
+// panicStack returns the stack starting at the panic site, skipping the frames of the panic
+// handling: the deferred calls, this package and the runtime. It honours the depth of the trace
+// options, so with TraceCaller the single frame kept is the one that panicked. Out of a panic the
+// stack starts at the caller of FromPanic.
+func panicStack() []uintptr {
+	pcs := make([]uintptr, panicStackBuffer+v2.MaxStackDepth())
+	// skips runtime.Callers, the first frame is panicStack and the second FromPanic
+	pcs = pcs[:runtime.Callers(1, pcs)]
+
+	start := 2
+	for i, pc := range pcs {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			start = i + 1
+			break
+		}
+	}
+	// runtime helpers like the ones raising nil map and index panics sit above the panic site
+	for start < len(pcs) {
+		fn := runtime.FuncForPC(pcs[start] - 1)
+		if fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+			break
+		}
+		start++
+	}
+	if start > len(pcs) {
+		start = len(pcs)
+	}
+
+	stack := pcs[start:]
+	if len(stack) > v2.MaxStackDepth() {
+		stack = stack[:v2.MaxStackDepth()]
+	}
+
+	return stack
+}
+
+// PanicValue returns the value of the panic err was created from by FromPanic.
+func PanicValue(err error) (interface{}, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	value := merry.Value(err, fieldKey(PanicField))
+	return value, value != nil
+}
+
+// Recover converts a panic into an error assigned to *errp. It must be deferred directly:
+//
+//	func (s *Service) Do() (err error) {
+//		defer errors.Recover(&err)
+//		...
+//	}
+func Recover(errp *error) {
+	if recovered := recover(); recovered != nil {
+		*errp = FromPanic(recovered)
+	}
+}
+
+// Safe calls fn returning its panics as errors.
+func Safe(fn func() error) (err error) {
+	defer Recover(&err)
+
+	return fn()
+}
+
+// SafeGo runs fn in a new goroutine and sends its error, or its panic as an error, on the
+// returned channel, which is closed afterwards.
+func SafeGo(fn func() error) <-chan error {
+	done := make(chan error, 1)
+
+	go func() {
+		defer close(done)
+		done <- Safe(fn)
+	}()
+
+	return done
+}
@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errVaultLocked = New("vault locked")
+
+func panicking(v interface{}) (err error) {
+	defer Recover(&err)
+
+	panic(v)
+}
+
+func Test_Recover(t *testing.T) {
+	testCases := []struct {
+		name            string
+		value           interface{}
+		expectedMessage string
+		expectedIs      error
+	}{
+		{
+			name:            "should convert values",
+			value:           "index out of range",
+			expectedMessage: "panic: index out of range",
+		},
+		{
+			name:            "should keep errors matchable",
+			value:           WithKind(errVaultLocked, Conflict),
+			expectedMessage: "panic: vault locked",
+			expectedIs:      errVaultLocked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := panicking(tc.value)
+			require.Error(t, err)
+			require.Equal(t, tc.expectedMessage, err.Error())
+			require.Equal(t, Internal, KindOf(err))
+			require.Equal(t, tc.value, Fields(err)[PanicField])
+			if tc.expectedIs != nil {
+				require.True(t, Is(err, tc.expectedIs))
+			}
+
+			// the trace starts at the panic site
+			trace := GetCauseFromError(err).Trace
+			require.NotEmpty(t, trace)
+			require.Equal(t, "github.com/mtavano/devkit/errors.panicking", trace[0].Function)
+		})
+	}
+
+	require.NoError(t, Safe(func() error { return nil }))
+	require.Nil(t, FromPanic(nil))
+}
+
+func Test_Recover_TraceCaller(t *testing.T) {
+	SetTraceOptions(TraceOptions{Mode: TraceCaller})
+	defer SetTraceOptions(TraceOptions{})
+
+	err := Safe(func() error {
+		var balances map[string]int
+		balances["vault 7"] = 10
+		return nil
+	})
+
+	trace := GetCauseFromError(err).Trace
+	require.Len(t, trace, 1)
+	require.Equal(t, "github.com/mtavano/devkit/errors.Test_Recover_TraceCaller.func1", trace[0].Function)
+
+	trace = GetCauseFromError(panicking("index out of range")).Trace
+	require.Len(t, trace, 1)
+	require.Equal(t, "github.com/mtavano/devkit/errors.panicking", trace[0].Function)
+}
+
+func Test_SafeGo(t *testing.T) {
+	err := <-SafeGo(func() error {
+		var values map[string]int
+		values["boom"] = 1
+		return nil
+	})
+	require.Equal(t, Internal, KindOf(err))
+	require.Contains(t, err.Error(), "assignment to entry in nil map")
+
+	err = <-SafeGo(func() error { return errVaultLocked })
+	require.Equal(t, errVaultLocked, err)
+}
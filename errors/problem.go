@@ -0,0 +1,111 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// ProblemContentType is the media type of RFC 7807 problem details
+	ProblemContentType = "application/problem+json"
+	// ProblemTypeDefault is the problem type used when the error has no more specific one
+	ProblemTypeDefault = "about:blank"
+)
+
+// Problem is an RFC 7807 problem details body. It only carries what is safe to show to the
+// caller: the message comes from WithUserMessage and internal messages, values and traces are
+// never rendered.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions are extra members rendered at the top level of the body
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem builds the problem details of err in the default locale of DefaultCatalog.
+func NewProblem(err error) *Problem {
+	return NewLocalizedProblem(err, DefaultCatalog.DefaultLocale())
+}
+
+// NewLocalizedProblem builds the problem details of err with its detail in locale. The status
+// comes from WithHTTPCode, or from the error kind when no code was attached.
+func NewLocalizedProblem(err error, locale string) *Problem {
+	status := HTTPCode(err)
+
+	problem := &Problem{
+		Type:       ProblemTypeDefault,
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     Redact(DefaultCatalog.Localize(err, locale)),
+		Extensions: map[string]interface{}{},
+	}
+
+	if kind := KindOf(err); kind != Unknown {
+		problem.Extensions["kind"] = kind
+	}
+	if code := CodeOf(err); code != "" {
+		problem.Extensions["code"] = code
+	}
+	if validation, ok := ValidationErrorsOf(err); ok {
+		params := make([]FieldError, 0, len(validation))
+		for _, fe := range validation {
+			fe.Message = Redact(fe.Message)
+			params = append(params, fe)
+		}
+		problem.Extensions["invalid-params"] = params
+	}
+
+	return problem
+}
+
+// MarshalJSON renders the problem members along with its extensions.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	body := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		body[k] = v
+	}
+
+	body["type"] = p.Type
+	body["title"] = p.Title
+	body["status"] = p.Status
+	if p.Detail != "" {
+		body["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		body["instance"] = p.Instance
+	}
+
+	return json.Marshal(body)
+}
+
+// Write renders the problem as an application/problem+json response.
+func (p *Problem) Write(w http.ResponseWriter) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return Wrap(err, "errors: Problem.Write json.Marshal error")
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	_, err = w.Write(body)
+	if err != nil {
+		return Wrap(err, "errors: Problem.Write w.Write error")
+	}
+
+	return nil
+}
+
+// WriteProblem renders err as an application/problem+json response. The request, when given, is
+// used as the problem instance and to pick the locale of the detail.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) error {
+	problem := NewLocalizedProblem(err, DefaultCatalog.RequestLocale(r))
+	if r != nil && r.URL != nil {
+		problem.Instance = r.URL.Path
+	}
+
+	return problem.Write(w)
+}
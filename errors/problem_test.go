@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteProblem(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expectedBody map[string]interface{}
+	}{
+		{
+			name: "should render unclassified errors as internal server errors",
+			err:  New("pq: connection refused to 10.0.0.1"),
+			expectedBody: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Internal Server Error",
+				"status":   float64(500),
+				"instance": "/accounts/acc_123",
+			},
+		},
+		{
+			name: "should use the attached http code and user message",
+			err:  WithUserMessage(WithHTTPCode(New("fintoc: movement mov_123 is locked"), http.StatusConflict), "The movement can't be updated"),
+			expectedBody: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Conflict",
+				"status":   float64(409),
+				"detail":   "The movement can't be updated",
+				"instance": "/accounts/acc_123",
+			},
+		},
+		{
+			name: "should use the kind when there is no http code",
+			err:  Wrap(WithKind(New("account not found"), NotFound), "fintoc: Client.GetAccounts error"),
+			expectedBody: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Not Found",
+				"status":   float64(404),
+				"kind":     "not_found",
+				"instance": "/accounts/acc_123",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/accounts/acc_123", nil)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, WriteProblem(rec, req, tc.err))
+			require.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+			require.Equal(t, int(tc.expectedBody["status"].(float64)), rec.Code)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Equal(t, tc.expectedBody, body)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+
+	"github.com/ansel1/merry"
+	v2 "github.com/ansel1/merry/v2"
+)
+
+// Redacted is the text rendered in place of sensitive data.
+const Redacted = "[REDACTED]"
+
+// SensitiveValue holds a value that must never be rendered. Printing, logging or encoding it
+// always produces Redacted, while Value still gives access to the original.
+type SensitiveValue struct {
+	value interface{}
+}
+
+// Sensitive marks value as sensitive.
+func Sensitive(value interface{}) SensitiveValue {
+	return SensitiveValue{value: value}
+}
+
+// Value returns the original value.
+func (s SensitiveValue) Value() interface{} {
+	return s.value
+}
+
+func (s SensitiveValue) String() string {
+	return Redacted
+}
+
+// Format implements fmt.Formatter so no verb or flag can print the original value.
+func (s SensitiveValue) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, Redacted)
+}
+
+func (s SensitiveValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+func (s SensitiveValue) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+// WithSensitiveField attaches a field whose value is never rendered, see Sensitive.
+func WithSensitiveField(err error, key string, value interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return merry.WrapSkipping(err, 1, v2.WithValue(fieldKey(key), Sensitive(value)))
+}
+
+// Redactor removes sensitive data from a text.
+type Redactor interface {
+	Redact(s string) string
+}
+
+// RedactorFunc adapts a function to a Redactor.
+type RedactorFunc func(s string) string
+
+func (fn RedactorFunc) Redact(s string) string {
+	return fn(s)
+}
+
+// NewPatternRedactor returns a Redactor replacing every match of pattern with replacement, which
+// may reference the pattern groups like regexp.ReplaceAllString.
+func NewPatternRedactor(pattern *regexp.Regexp, replacement string) Redactor {
+	return RedactorFunc(func(s string) string {
+		return pattern.ReplaceAllString(s, replacement)
+	})
+}
+
+type namedRedactor struct {
+	name     string
+	redactor Redactor
+}
+
+var (
+	redactorsMu sync.RWMutex
+	redactors   = []namedRedactor{
+		{name: "jwt", redactor: NewPatternRedactor(regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Redacted)},
+		{name: "api_key", redactor: apiKeyRedactor},
+		{name: "clabe", redactor: NewPatternRedactor(regexp.MustCompile(`\b\d{18}\b`), Redacted)},
+		{name: "card_pan", redactor: RedactorFunc(redactCardPANs)},
+		{name: "rut", redactor: NewPatternRedactor(regexp.MustCompile(`\b(?:\d{1,2}\.\d{3}\.\d{3}|\d{7,8})-[\dkK]\b`), Redacted)},
+	}
+)
+
+var (
+	// fintoc style secret keys: sk_live_..., pk_test_...
+	apiKeyPrefixedPattern = regexp.MustCompile(`\b(?:sk|pk)_(?:live|test)_[A-Za-z0-9]+`)
+	bearerPattern         = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]+=*`)
+	// values of keys like "X-API-Key: ...", api_key=... or "secret": "..."
+	apiKeyAssignmentPattern = regexp.MustCompile(`(?i)(\b(?:x-)?api[_-]?key|\bapi[_-]?secret|\bsecret|\baccess[_-]?token|\bpassword)("?\s*[:=]\s*"?)[^\s",&\[]+`)
+)
+
+var apiKeyRedactor = RedactorFunc(func(s string) string {
+	s = apiKeyPrefixedPattern.ReplaceAllString(s, Redacted)
+	s = bearerPattern.ReplaceAllString(s, "${1}"+Redacted)
+	return apiKeyAssignmentPattern.ReplaceAllString(s, "${1}${2}"+Redacted)
+})
+
+var cardPANPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// redactCardPANs replaces card numbers, only when they pass the Luhn check to leave other long
+// numbers alone.
+func redactCardPANs(s string) string {
+	return cardPANPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if luhnValid(match) {
+			return Redacted
+		}
+		return match
+	})
+}
+
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// RegisterRedactor adds a redactor applied by Redact, replacing the one registered with the same
+// name. The built-in ones are named jwt, api_key, clabe, card_pan and rut.
+func RegisterRedactor(name string, redactor Redactor) {
+	redactorsMu.Lock()
+	defer redactorsMu.Unlock()
+
+	for i := range redactors {
+		if redactors[i].name == name {
+			redactors[i].redactor = redactor
+			return
+		}
+	}
+
+	redactors = append(redactors, namedRedactor{name: name, redactor: redactor})
+}
+
+// UnregisterRedactor removes the redactor registered with name.
+func UnregisterRedactor(name string) {
+	redactorsMu.Lock()
+	defer redactorsMu.Unlock()
+
+	for i := range redactors {
+		if redactors[i].name == name {
+			redactors = append(redactors[:i], redactors[i+1:]...)
+			return
+		}
+	}
+}
+
+// Redact applies every registered redactor to s. GetCauseFromError, and so the JSON, problem and
+// log renderers, apply it to messages and values.
+func Redact(s string) string {
+	redactorsMu.RLock()
+	defer redactorsMu.RUnlock()
+
+	for _, r := range redactors {
+		s = r.redactor.Redact(s)
+	}
+
+	return s
+}
+
+// redactValue hides sensitive values and redacts strings. Other values are kept as they are.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case SensitiveValue:
+		return v
+	case string:
+		return Redact(v)
+	case error:
+		return Redact(v.Error())
+	default:
+		return value
+	}
+}
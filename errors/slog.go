@@ -0,0 +1,118 @@
+package errors
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// LogValue renders the cause as a group with its message, kind, values, http code and a compact
+// trace.
+func (ec *ErrorCause) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("message", ec.errMsg),
+	}
+
+	if ec.Kind != Unknown {
+		attrs = append(attrs, slog.String("kind", ec.Kind.String()))
+	}
+
+	if len(ec.Values) > 0 {
+		keys := make([]string, 0, len(ec.Values))
+		for k := range ec.Values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		values := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			values = append(values, slog.Any(k, ec.Values[k]))
+		}
+		attrs = append(attrs, slog.Group("values", values...))
+	}
+
+	attrs = append(attrs, slog.Int("http_code", HTTPCode(ec)))
+
+	if len(ec.Trace) > 0 {
+		attrs = append(attrs, slog.String("trace", ec.Trace.Compact()))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue renders err the same way ErrorCause does.
+func LogValue(err error) slog.Value {
+	if err == nil {
+		return slog.AnyValue(nil)
+	}
+
+	return logCause(err).LogValue()
+}
+
+// Attr returns an "error" attribute rendering err with LogValue.
+func Attr(err error) slog.Attr {
+	return slog.Attr{Key: "error", Value: LogValue(err)}
+}
+
+func logCause(err error) *ErrorCause {
+	if cause, ok := err.(*ErrorCause); ok {
+		return cause
+	}
+
+	return GetCauseFromError(err)
+}
+
+// LogHandler is a slog.Handler that renders every error attribute with LogValue before passing
+// the record to the wrapped handler.
+type LogHandler struct {
+	next slog.Handler
+}
+
+func NewLogHandler(next slog.Handler) *LogHandler {
+	return &LogHandler{next: next}
+}
+
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	expanded := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		expanded.AddAttrs(expandErrors(attr))
+		return true
+	})
+
+	return h.next.Handle(ctx, expanded)
+}
+
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		expanded = append(expanded, expandErrors(attr))
+	}
+
+	return &LogHandler{next: h.next.WithAttrs(expanded)}
+}
+
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{next: h.next.WithGroup(name)}
+}
+
+func expandErrors(attr slog.Attr) slog.Attr {
+	switch attr.Value.Kind() {
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok && err != nil {
+			return slog.Attr{Key: attr.Key, Value: LogValue(err)}
+		}
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		expanded := make([]slog.Attr, 0, len(group))
+		for _, member := range group {
+			expanded = append(expanded, expandErrors(member))
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(expanded...)}
+	}
+
+	return attr
+}
@@ -0,0 +1,56 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(buf, nil)))
+
+	err := WithField(
+		WithHTTPCode(WithKind(New("account not found"), NotFound), http.StatusNotFound),
+		"account_type", "checking_account",
+	)
+
+	logger.With("service", "payouts").Error(
+		"could not fetch accounts",
+		"error", Wrap(err, "fintoc: Client.GetAccounts error"),
+		slog.Group("request", "previous_error", New("retry")),
+	)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "payouts", entry["service"])
+
+	logged := entry["error"].(map[string]interface{})
+	require.Equal(t, "fintoc: Client.GetAccounts error: account not found", logged["message"])
+	require.Equal(t, "not_found", logged["kind"])
+	require.Equal(t, float64(http.StatusNotFound), logged["http_code"])
+	require.Equal(t, map[string]interface{}{"account_type": "checking_account"}, logged["values"])
+	require.Contains(t, logged["trace"], "errors.Test_LogHandler(errors/slog_test.go:")
+
+	previous := entry["request"].(map[string]interface{})["previous_error"].(map[string]interface{})
+	require.Equal(t, "retry", previous["message"])
+}
+
+func Test_ErrorCause_LogValue(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+
+	logger.Error("failed", "error", GetCauseFromError(WithKind(New("too many requests"), RateLimited)))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+	logged := entry["error"].(map[string]interface{})
+	require.Equal(t, "too many requests", logged["message"])
+	require.Equal(t, "rate_limited", logged["kind"])
+	require.Equal(t, float64(http.StatusTooManyRequests), logged["http_code"])
+}
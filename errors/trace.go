@@ -0,0 +1,259 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	v2 "github.com/ansel1/merry/v2"
+)
+
+// TraceMode sets how much of the stack is captured when an error is created.
+type TraceMode int
+
+const (
+	// TraceFull captures the whole stack, up to TraceOptions.MaxDepth frames
+	TraceFull TraceMode = iota
+	// TraceCaller captures only the frame that created the error
+	TraceCaller
+	// TraceDisabled captures no stack at all, for hot paths
+	TraceDisabled
+)
+
+const defaultTraceDepth = 50
+
+// TraceOptions configures stack capture and the frames kept in traces. They apply globally, to
+// every merry error of the process, so they are meant to be set once at startup with
+// SetTraceOptions.
+type TraceOptions struct {
+	Mode TraceMode
+	// MaxDepth is the number of frames captured in TraceFull mode. Defaults to 50.
+	MaxDepth int
+	// ModulePath is the import path stripped from file paths. Defaults to the main module.
+	ModulePath string
+	// ExcludePrefixes are function prefixes dropped from traces on top of the runtime, testing
+	// and error handling internals.
+	ExcludePrefixes []string
+}
+
+var (
+	traceMu   sync.RWMutex
+	traceOpts = TraceOptions{
+		Mode:       TraceFull,
+		MaxDepth:   defaultTraceDepth,
+		ModulePath: mainModulePath(),
+	}
+)
+
+// internalPrefixes are the functions never worth showing in a trace.
+var internalPrefixes = []string{
+	"runtime.",
+	"testing.",
+	"github.com/ansel1/merry",
+	"github.com/pkg/errors.",
+}
+
+const errorsPkgPath = "github.com/mtavano/devkit/errors."
+
+// SetTraceOptions replaces the trace configuration.
+//
+// The capture mode and depth are merry settings, which are global to the process: they also
+// apply to the errors created with merry outside devkit, by any other package of the binary.
+func SetTraceOptions(opts TraceOptions) {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = defaultTraceDepth
+	}
+	if opts.ModulePath == "" {
+		opts.ModulePath = mainModulePath()
+	}
+
+	traceMu.Lock()
+	defer traceMu.Unlock()
+
+	traceOpts = opts
+	switch opts.Mode {
+	case TraceDisabled:
+		v2.SetStackCaptureEnabled(false)
+	case TraceCaller:
+		v2.SetStackCaptureEnabled(true)
+		v2.SetMaxStackDepth(1)
+	default:
+		v2.SetStackCaptureEnabled(true)
+		v2.SetMaxStackDepth(opts.MaxDepth)
+	}
+}
+
+// GetTraceOptions returns the current trace configuration.
+func GetTraceOptions() TraceOptions {
+	traceMu.RLock()
+	defer traceMu.RUnlock()
+
+	return traceOpts
+}
+
+type Frame struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// Trace is a captured stack, innermost call first.
+type Trace []Frame
+
+// String renders the trace in the multi-line format used by %+v, one function per line followed
+// by its indented location.
+func (t Trace) String() string {
+	var sb strings.Builder
+	for i, frame := range t {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+
+	return sb.String()
+}
+
+// Compact renders the trace in a single line with package-qualified function names.
+func (t Trace) Compact() string {
+	parts := make([]string, 0, len(t))
+	for _, frame := range t {
+		function := frame.Function
+		if idx := strings.LastIndex(function, "/"); idx >= 0 {
+			function = function[idx+1:]
+		}
+		parts = append(parts, fmt.Sprintf("%s(%s:%d)", function, frame.File, frame.Line))
+	}
+
+	return strings.Join(parts, " < ")
+}
+
+// Format implements fmt.Formatter. %+v renders the message followed by the trace, any other verb
+// just the message.
+func (ec *ErrorCause) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+') && len(ec.Trace) > 0:
+		fmt.Fprintf(s, "%s\n%s", ec.errMsg, ec.Trace)
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", ec.errMsg)
+	default:
+		fmt.Fprint(s, ec.errMsg)
+	}
+}
+
+func makeTrace(err error) Trace {
+	stack := v2.Stack(err)
+	if len(stack) == 0 {
+		return nil
+	}
+
+	opts := GetTraceOptions()
+
+	ff := Trace{}
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !excludeFrame(frame, opts) {
+			ff = append(ff, Frame{
+				Function: frame.Function,
+				File:     relativePath(frame, opts.ModulePath),
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	return ff
+}
+
+func excludeFrame(frame runtime.Frame, opts TraceOptions) bool {
+	// the package's own tests are the only callers worth keeping from this package
+	if strings.HasPrefix(frame.Function, errorsPkgPath) && !strings.HasSuffix(frame.File, "_test.go") {
+		return true
+	}
+
+	for _, prefix := range internalPrefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+	for _, prefix := range opts.ExcludePrefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// relativePath rebuilds the file path from the package of the function, which doesn't depend on
+// where the module was built, and strips the module path from it.
+func relativePath(frame runtime.Frame, modulePath string) string {
+	pkg, _ := SplitFunction(frame.Function)
+	file := frame.File
+	if idx := strings.LastIndex(file, "/"); idx >= 0 {
+		file = file[idx+1:]
+	}
+
+	if pkg == "" || pkg == "main" {
+		return file
+	}
+
+	path := pkg + "/" + file
+	if modulePath != "" {
+		path = strings.TrimPrefix(path, modulePath+"/")
+	}
+
+	return path
+}
+
+// SplitFunction splits a fully qualified function name like
+// github.com/mtavano/devkit/clients/fintoc.(*Client).scanBody into its import path and the name
+// of the function in the package. The runtime escapes the dots of the last path element, as in
+// gopkg.in/yaml%2ev3.Unmarshal, names without that escaping are expected to only have dots in
+// major version suffixes like .v3.
+func SplitFunction(function string) (string, string) {
+	slash := strings.LastIndex(function, "/")
+	if slash < 0 {
+		slash = 0
+	}
+
+	end := slash
+	for {
+		dot := strings.Index(function[end:], ".")
+		if dot < 0 {
+			return "", function
+		}
+		end += dot
+
+		if !isVersionSuffix(function[end+1:]) {
+			break
+		}
+		end++
+	}
+
+	return strings.ReplaceAll(function[:end], "%2e", "."), function[end+1:]
+}
+
+// isVersionSuffix reports whether s starts with a major version path element like v3 followed by
+// the rest of the function name.
+func isVersionSuffix(s string) bool {
+	if len(s) < 3 || s[0] != 'v' {
+		return false
+	}
+
+	digits := strings.IndexFunc(s[1:], func(r rune) bool { return r < '0' || r > '9' })
+	return digits > 0 && s[1+digits] == '.'
+}
+
+func mainModulePath() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	return info.Main.Path
+}
@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetCauseFromError_Trace(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           TraceOptions
+		expectedFrames int
+	}{
+		{
+			name:           "should capture the whole stack without internal frames",
+			opts:           TraceOptions{Mode: TraceFull},
+			expectedFrames: -1,
+		},
+		{
+			name:           "should capture only the caller",
+			opts:           TraceOptions{Mode: TraceCaller},
+			expectedFrames: 1,
+		},
+		{
+			name:           "should not capture when disabled",
+			opts:           TraceOptions{Mode: TraceDisabled},
+			expectedFrames: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetTraceOptions(tc.opts)
+			defer SetTraceOptions(TraceOptions{})
+
+			trace := GetCauseFromError(Wrap(New("account not found"), "fintoc: Client.GetAccounts error")).Trace
+			if tc.expectedFrames >= 0 {
+				require.Len(t, trace, tc.expectedFrames)
+			}
+			if len(trace) == 0 {
+				return
+			}
+
+			require.Equal(t, "github.com/mtavano/devkit/errors.Test_GetCauseFromError_Trace.func1", trace[0].Function)
+			require.Equal(t, "errors/trace_test.go", trace[0].File)
+			for _, frame := range trace {
+				require.False(t, strings.HasPrefix(frame.Function, "runtime."), frame.Function)
+				require.False(t, strings.HasPrefix(frame.Function, "testing."), frame.Function)
+			}
+		})
+	}
+}
+
+func Test_Trace_Format(t *testing.T) {
+	trace := Trace{
+		{Function: "github.com/mtavano/devkit/clients/fintoc.(*Client).scanBody", File: "clients/fintoc/fintoc.go", Line: 85},
+		{Function: "github.com/mtavano/devkit/clients/fintoc.(*Client).GetAccounts", File: "clients/fintoc/get_accounts.go", Line: 36},
+	}
+
+	require.Equal(t,
+		"github.com/mtavano/devkit/clients/fintoc.(*Client).scanBody\n"+
+			"\tclients/fintoc/fintoc.go:85\n"+
+			"github.com/mtavano/devkit/clients/fintoc.(*Client).GetAccounts\n"+
+			"\tclients/fintoc/get_accounts.go:36",
+		trace.String(),
+	)
+	require.Equal(t,
+		"fintoc.(*Client).scanBody(clients/fintoc/fintoc.go:85) < fintoc.(*Client).GetAccounts(clients/fintoc/get_accounts.go:36)",
+		trace.Compact(),
+	)
+
+	cause := &ErrorCause{errMsg: "bad request", Trace: trace}
+	require.Equal(t, "bad request", fmt.Sprintf("%v", cause))
+	require.Equal(t, "bad request\n"+trace.String(), fmt.Sprintf("%+v", cause))
+}
+
+func Test_SplitFunction(t *testing.T) {
+	testCases := []struct {
+		name             string
+		function         string
+		expectedPackage  string
+		expectedFunction string
+	}{
+		{
+			name:             "should split methods",
+			function:         "github.com/mtavano/devkit/clients/fintoc.(*Client).scanBody",
+			expectedPackage:  "github.com/mtavano/devkit/clients/fintoc",
+			expectedFunction: "(*Client).scanBody",
+		},
+		{
+			name:             "should split standard library functions",
+			function:         "net/http.(*conn).serve",
+			expectedPackage:  "net/http",
+			expectedFunction: "(*conn).serve",
+		},
+		{
+			name:             "should split the main package",
+			function:         "main.main",
+			expectedPackage:  "main",
+			expectedFunction: "main",
+		},
+		{
+			name:             "should keep names without package",
+			function:         "unknown",
+			expectedFunction: "unknown",
+		},
+		{
+			name:             "should unescape the dots of the last path element",
+			function:         "gopkg.in/yaml%2ev3.(*decoder).unmarshal",
+			expectedPackage:  "gopkg.in/yaml.v3",
+			expectedFunction: "(*decoder).unmarshal",
+		},
+		{
+			name:             "should keep version suffixes",
+			function:         "gopkg.in/yaml.v3.Unmarshal",
+			expectedPackage:  "gopkg.in/yaml.v3",
+			expectedFunction: "Unmarshal",
+		},
+		{
+			name:             "should keep version path elements",
+			function:         "github.com/ansel1/merry/v2.Wrap",
+			expectedPackage:  "github.com/ansel1/merry/v2",
+			expectedFunction: "Wrap",
+		},
+		{
+			name:             "should not take functions for versions",
+			function:         "github.com/mtavano/devkit/errors.v2",
+			expectedPackage:  "github.com/mtavano/devkit/errors",
+			expectedFunction: "v2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg, function := SplitFunction(tc.function)
+			require.Equal(t, tc.expectedPackage, pkg)
+			require.Equal(t, tc.expectedFunction, function)
+		})
+	}
+}
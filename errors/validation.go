@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ansel1/merry"
+	v2 "github.com/ansel1/merry/v2"
+)
+
+// FieldError is a problem with a single field of a validated input.
+type FieldError struct {
+	// Field is the JSON pointer of the field, like /items/0/amount
+	Field string `json:"name"`
+	// Rule is the code of the failed rule, like required or max
+	Rule    string `json:"rule"`
+	Message string `json:"reason"`
+}
+
+// ValidationErrors collects every field problem of an input instead of failing on the first one.
+// Its kind is Invalid and it renders as the invalid-params member of problem responses.
+type ValidationErrors []FieldError
+
+// Add records a problem with field, a JSON pointer built with FieldPath.
+func (v *ValidationErrors) Add(field, rule, message string) {
+	*v = append(*v, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// Addf is Add with a formatted message.
+func (v *ValidationErrors) Addf(field, rule, format string, args ...interface{}) {
+	v.Add(field, rule, fmt.Sprintf(format, args...))
+}
+
+func (v ValidationErrors) Error() string {
+	parts := make([]string, 0, len(v))
+	for _, fe := range v {
+		parts = append(parts, fmt.Sprintf("%s: %s", fe.Field, fe.Message))
+	}
+
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Err returns nil when there are no problems, otherwise the problems as an Invalid error with
+// a 400 HTTP code. Validation methods are expected to return it.
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return merry.WrapSkipping(v, 1, v2.WithValue(kindKey{}, Invalid), v2.WithHTTPCode(http.StatusBadRequest))
+}
+
+// ValidationErrorsOf returns the validation problems found in err's chain.
+func ValidationErrorsOf(err error) (ValidationErrors, bool) {
+	var v ValidationErrors
+	if As(err, &v) {
+		return v, true
+	}
+
+	return nil, false
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// FieldPath builds the JSON pointer of a field from its path segments, so FieldPath("items", "0",
+// "amount") is /items/0/amount.
+func FieldPath(segments ...string) string {
+	var sb strings.Builder
+	for _, segment := range segments {
+		sb.WriteString("/")
+		sb.WriteString(pointerEscaper.Replace(segment))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidationErrors(t *testing.T) {
+	var errs ValidationErrors
+	require.NoError(t, errs.Err())
+
+	errs.Add(FieldPath("amount"), "min", "must be positive")
+	errs.Addf(FieldPath("beneficiary", "account/number"), "length", "must have %d digits", 18)
+
+	err := Wrap(errs.Err(), "transfers: Service.Create req.Validate error")
+	require.Equal(t, "transfers: Service.Create req.Validate error: validation failed: /amount: must be positive; /beneficiary/account~1number: must have 18 digits", err.Error())
+	require.Equal(t, Invalid, KindOf(err))
+	require.Equal(t, Invalid, KindOf(errs))
+	require.Equal(t, http.StatusBadRequest, HTTPCode(err))
+
+	validation, ok := ValidationErrorsOf(err)
+	require.True(t, ok)
+	require.Len(t, validation, 2)
+	require.Equal(t, "length", validation[1].Rule)
+
+	_, ok = ValidationErrorsOf(New("not a validation error"))
+	require.False(t, ok)
+}
+
+func Test_WriteProblem_InvalidParams(t *testing.T) {
+	var errs ValidationErrors
+	errs.Add(FieldPath("max_items"), "max", "must be at most 300")
+	errs.Add(FieldPath("token"), "format", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig is malformed")
+
+	req := httptest.NewRequest(http.MethodGet, "/movements", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, WriteProblem(rec, req, errs.Err()))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	body := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"name": "/max_items", "rule": "max", "reason": "must be at most 300"},
+		map[string]interface{}{"name": "/token", "rule": "format", "reason": "[REDACTED] is malformed"},
+	}, body["invalid-params"])
+}
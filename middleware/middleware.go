@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+
+	"github.com/mtavano/devkit/errors"
+)
+
+const (
+	// DefaultRequestIDHeader is the header used to read and write the request ID
+	DefaultRequestIDHeader = "X-Request-ID"
+)
+
+// HandlerFunc is an http handler that returns its error instead of writing it to the response.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ErrorLogger receives the internal details of every error rendered by the middleware.
+type ErrorLogger func(r *http.Request, cause *errors.ErrorCause)
+
+type Options struct {
+	// RequestIDHeader is the header used to read and write the request ID. Defaults to
+	// DefaultRequestIDHeader.
+	RequestIDHeader string
+	// Logger receives the errors rendered to the users. Defaults to the slog default logger.
+	Logger ErrorLogger
+}
+
+// Middleware translates errors returned by handlers, and panics raised by them, into
+// application/problem+json responses. Users only get the status and the message attached with
+// WithUserMessage while the internal details are logged.
+type Middleware struct {
+	requestIDHeader string
+	logger          ErrorLogger
+}
+
+func New(opts *Options) *Middleware {
+	m := &Middleware{
+		requestIDHeader: DefaultRequestIDHeader,
+		logger:          logError,
+	}
+
+	if opts == nil {
+		return m
+	}
+	if opts.RequestIDHeader != "" {
+		m.requestIDHeader = opts.RequestIDHeader
+	}
+	if opts.Logger != nil {
+		m.logger = opts.Logger
+	}
+
+	return m
+}
+
+// Wrap adds a request ID to the request context and the response, and recovers panics raised by
+// next into 500 responses.
+func (m *Middleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// an outer Wrap already assigned the request ID
+		requestID := RequestIDFromContext(r.Context())
+		if requestID == "" {
+			requestID = r.Header.Get(m.requestIDHeader)
+		}
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, requestID))
+		w.Header().Set(m.requestIDHeader, requestID)
+
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			// the stack is captured here, while the panicking frames are still on it
+			m.renderError(rw, r, errors.FromPanic(recovered))
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// Handle adapts fn to an http.Handler rendering the returned error as a problem response. It
+// also applies Wrap, so it can be mounted directly.
+func (m *Middleware) Handle(fn HandlerFunc) http.Handler {
+	return m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := fn(w, r)
+		if err != nil {
+			m.renderError(w.(*responseWriter), r, err)
+		}
+	}))
+}
+
+// renderError logs err and renders it, unless the handler already started the response.
+func (m *Middleware) renderError(w *responseWriter, r *http.Request, err error) {
+	m.logger(r, errors.GetCauseFromError(err))
+	if w.wroteHeader {
+		return
+	}
+
+	problem := errors.NewLocalizedProblem(err, errors.DefaultCatalog.RequestLocale(r))
+	problem.Instance = r.URL.Path
+	if requestID := RequestIDFromContext(r.Context()); requestID != "" {
+		problem.Extensions["request_id"] = requestID
+	}
+
+	if err := problem.Write(w); err != nil {
+		m.logger(r, errors.GetCauseFromError(err))
+	}
+}
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID set by the middleware, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func logError(r *http.Request, cause *errors.ErrorCause) {
+	slog.ErrorContext(
+		r.Context(),
+		"middleware: request failed",
+		"request_id", RequestIDFromContext(r.Context()),
+		"method", r.Method,
+		"path", r.URL.Path,
+		"error", cause,
+	)
+}
+
+// responseWriter records whether the response was started, after which an error can't be
+// rendered anymore.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	rw.wroteHeader = true
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the original writer.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mtavano/devkit/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Middleware_Handle(t *testing.T) {
+	testCases := []struct {
+		name           string
+		handler        HandlerFunc
+		requestID      string
+		expectedStatus int
+		expectedDetail string
+		expectedLogged bool
+	}{
+		{
+			name: "should not touch successful responses",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "should render the http code and user message of returned errors",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				err := errors.WithHTTPCode(errors.New("fintoc: account acc_123 not linked"), http.StatusUnprocessableEntity)
+				return errors.WithUserMessage(err, "The account is not linked")
+			},
+			requestID:      "req-123",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedDetail: "The account is not linked",
+			expectedLogged: true,
+		},
+		{
+			name: "should recover panics into internal server errors",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				var accounts map[string]string
+				accounts["acc_123"] = "checking_account"
+				return nil
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedLogged: true,
+		},
+		{
+			name: "should only log errors returned after the response started",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusAccepted)
+				return errors.New("stream closed")
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedLogged: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var logged *errors.ErrorCause
+			m := New(&Options{
+				Logger: func(r *http.Request, cause *errors.ErrorCause) {
+					logged = cause
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/accounts/acc_123", nil)
+			if tc.requestID != "" {
+				req.Header.Set(DefaultRequestIDHeader, tc.requestID)
+			}
+			rec := httptest.NewRecorder()
+
+			m.Handle(tc.handler).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedStatus, rec.Code)
+			requestID := rec.Header().Get(DefaultRequestIDHeader)
+			require.NotEmpty(t, requestID)
+			if tc.requestID != "" {
+				require.Equal(t, tc.requestID, requestID)
+			}
+
+			require.Equal(t, tc.expectedLogged, logged != nil)
+			if !tc.expectedLogged || rec.Code < http.StatusBadRequest {
+				return
+			}
+
+			require.Equal(t, errors.ProblemContentType, rec.Header().Get("Content-Type"))
+
+			var problem map[string]interface{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			require.Equal(t, float64(tc.expectedStatus), problem["status"])
+			require.Equal(t, requestID, problem["request_id"])
+			require.Equal(t, "/accounts/acc_123", problem["instance"])
+			if tc.expectedDetail != "" {
+				require.Equal(t, tc.expectedDetail, problem["detail"])
+			}
+			require.NotContains(t, rec.Body.String(), logged.Error())
+			require.NotEmpty(t, logged.Trace)
+		})
+	}
+}
+
+func Test_Middleware_Wrap_RequestID(t *testing.T) {
+	m := New(nil)
+
+	var inner string
+	handler := m.Wrap(m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner = RequestIDFromContext(r.Context())
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.NotEmpty(t, inner)
+	require.Equal(t, inner, rec.Header().Get(DefaultRequestIDHeader))
+}
@@ -0,0 +1,115 @@
+package promise
+
+import (
+	"context"
+	"time"
+
+	"github.com/mtavano/devkit/errors"
+)
+
+// ErrNoTasks is returned by Race and Any on groups without tasks, as they have no result to give.
+var ErrNoTasks = errors.WithKind(errors.New("promise: group has no tasks"), errors.Invalid)
+
+// Status is the outcome of a settled task.
+type Status int
+
+const (
+	Fulfilled Status = iota
+	Rejected
+)
+
+func (s Status) String() string {
+	if s == Rejected {
+		return "rejected"
+	}
+	return "fulfilled"
+}
+
+// Result is the outcome of a single task.
+type Result[T any] struct {
+	// Index is the position of the task in the group
+	Index    int
+	Status   Status
+	Value    T
+	Err      error
+	Duration time.Duration
+
+	// recovered is the panic value of the task when panics are propagated
+	recovered interface{}
+}
+
+// Race returns the result of the first task to finish, success or failure, and cancels the rest.
+// It doesn't wait for the cancelled tasks, so they must honour their context.
+func (g *GroupOf[T]) Race(ctx context.Context) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results, ok := g.start(ctx)
+	if !ok {
+		var zero T
+		return zero, ErrNoTasks
+	}
+
+	r := <-results
+	if r.recovered != nil {
+		panic(r.recovered)
+	}
+	return r.Value, r.Err
+}
+
+// Any returns the value of the first task to succeed and cancels the rest. When every task fails
+// it returns a *GroupError with their errors in completion order. Like Race it doesn't wait for
+// the cancelled tasks.
+func (g *GroupOf[T]) Any(ctx context.Context) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var zero T
+	results, ok := g.start(ctx)
+	if !ok {
+		return zero, ErrNoTasks
+	}
+
+	failures := make([]error, 0, len(g.tasks))
+	for range g.tasks {
+		r := <-results
+		if r.recovered != nil {
+			panic(r.recovered)
+		}
+		if r.Err == nil {
+			return r.Value, nil
+		}
+		failures = append(failures, r.Err)
+	}
+
+	return zero, &GroupError{Errs: failures}
+}
+
+// AllSettled executes every task no matter if any fails and returns the outcome of each of them,
+// indexed like the tasks.
+func (g *GroupOf[T]) AllSettled(ctx context.Context) []Result[T] {
+	results := make([]Result[T], len(g.tasks))
+	recovered := g.execute(ctx, func(r Result[T]) {
+		results[r.Index] = r
+	})
+	if recovered != nil {
+		panic(recovered)
+	}
+
+	return results
+}
+
+// start executes the tasks in the background, sending their results in completion order on a
+// channel large enough to never block them.
+func (g *GroupOf[T]) start(ctx context.Context) (<-chan Result[T], bool) {
+	if len(g.tasks) == 0 {
+		return nil, false
+	}
+
+	results := make(chan Result[T], len(g.tasks))
+	go g.execute(ctx, func(r Result[T]) {
+		results <- r
+	})
+
+	return results, true
+}
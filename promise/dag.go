@@ -0,0 +1,337 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mtavano/devkit/errors"
+)
+
+var (
+	// ErrInvalidDAG is returned by Build for duplicated nodes, unknown dependencies and cycles.
+	ErrInvalidDAG = errors.WithKind(errors.New("promise: invalid dag"), errors.Invalid)
+	// ErrSkipped is the error of the nodes skipped because a dependency failed.
+	ErrSkipped = errors.New("promise: node skipped")
+	// ErrNoResult is returned by ResultOf for unknown nodes and nodes that didn't run.
+	ErrNoResult = errors.New("promise: node has no result")
+)
+
+// NodeFunc is the work of a DAG node. The results of its dependencies are read from results with
+// ResultOf.
+type NodeFunc[T any] func(ctx context.Context, results *Results) (T, error)
+
+type node struct {
+	name       string
+	deps       []string
+	dependents []string
+	fn         func(ctx context.Context, results *Results) (interface{}, error)
+}
+
+// DAGBuilder declares the nodes of a DAG. Nodes are added with AddNode and checked by Build.
+type DAGBuilder struct {
+	nodes  []*node
+	byName map[string]*node
+	opts   []Option
+	err    error
+}
+
+// NewDAG starts the declaration of a DAG. WithConcurrency bounds how many nodes run at once,
+// WithRateLimit paces them and WithPanicPropagation makes Run panic again once the nodes settled.
+func NewDAG(opts ...Option) *DAGBuilder {
+	return &DAGBuilder{byName: make(map[string]*node), opts: opts}
+}
+
+// AddNode adds a node running fn once every node of deps succeeded. Repeated deps count once.
+func AddNode[T any](b *DAGBuilder, name string, fn NodeFunc[T], deps ...string) *DAGBuilder {
+	if b.err != nil {
+		return b
+	}
+	if _, ok := b.byName[name]; ok {
+		b.err = errors.Wrapf(ErrInvalidDAG, "promise: node %q declared twice", name)
+		return b
+	}
+
+	n := &node{
+		name: name,
+		deps: uniqueDeps(deps),
+		fn: func(ctx context.Context, results *Results) (interface{}, error) {
+			return fn(ctx, results)
+		},
+	}
+	b.nodes = append(b.nodes, n)
+	b.byName[name] = n
+
+	return b
+}
+
+func uniqueDeps(deps []string) []string {
+	seen := make(map[string]bool, len(deps))
+	unique := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		if !seen[dep] {
+			seen[dep] = true
+			unique = append(unique, dep)
+		}
+	}
+
+	return unique
+}
+
+// Build checks the declared nodes and returns the DAG ready to run.
+func (b *DAGBuilder) Build() (*DAG, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	for _, n := range b.nodes {
+		n.dependents = nil
+	}
+	for _, n := range b.nodes {
+		for _, dep := range n.deps {
+			parent, ok := b.byName[dep]
+			if !ok {
+				return nil, errors.Wrapf(ErrInvalidDAG, "promise: node %q depends on unknown node %q", n.name, dep)
+			}
+			parent.dependents = append(parent.dependents, n.name)
+		}
+	}
+
+	if cycle := b.findCycle(); cycle != nil {
+		return nil, errors.Wrapf(ErrInvalidDAG, "promise: cycle %s", strings.Join(cycle, " -> "))
+	}
+
+	return &DAG{nodes: b.nodes, byName: b.byName, opts: newOptions(b.opts)}, nil
+}
+
+// findCycle returns the path of a cycle, closed with its first node, or nil.
+func (b *DAGBuilder) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(b.nodes))
+	path := []string{}
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, dep := range b.byName[name].deps {
+			switch state[dep] {
+			case visiting:
+				for i, step := range path {
+					if step == dep {
+						return append(append([]string{}, path[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, n := range b.nodes {
+		if state[n.name] == unvisited {
+			if cycle := visit(n.name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// DAG runs nodes as soon as their dependencies succeed, so independent branches run concurrently.
+type DAG struct {
+	nodes  []*node
+	byName map[string]*node
+	opts   options
+}
+
+// Run executes the nodes and returns their results. Nodes whose dependencies failed are skipped
+// with ErrSkipped while the other branches go on. The error is the first failure by completion
+// time, or a *GroupError when more than one node failed. Skipped nodes aren't counted.
+func (d *DAG) Run(ctx context.Context) (*Results, error) {
+	results := &Results{values: make(map[string]interface{}), errs: make(map[string]error)}
+
+	pending := make(map[string]int, len(d.nodes))
+	blockedBy := make(map[string]string)
+	for _, n := range d.nodes {
+		pending[n.name] = len(n.deps)
+	}
+
+	var sem chan struct{}
+	if d.opts.concurrency > 0 {
+		sem = make(chan struct{}, d.opts.concurrency)
+	}
+
+	type completion struct {
+		name      string
+		err       error
+		recovered interface{}
+	}
+	done := make(chan completion, len(d.nodes))
+	running := 0
+	failures := []error{}
+	var firstPanic interface{}
+
+	launch := func(n *node) {
+		running++
+		go func() {
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+
+			v, err, panicked := runGuarded(ctx, d.opts, func(ctx context.Context) (interface{}, error) {
+				return n.fn(ctx, results)
+			})
+			if err == nil {
+				results.set(n.name, v)
+			}
+
+			c := completion{name: n.name, err: err}
+			if panicked && d.opts.propagatePanics {
+				c.recovered, _ = errors.PanicValue(err)
+			}
+			done <- c
+		}()
+	}
+
+	// settle releases the dependents of a node that succeeded, failed or was skipped
+	var settle func(name string, failed bool)
+	settle = func(name string, failed bool) {
+		for _, dependent := range d.byName[name].dependents {
+			if failed && blockedBy[dependent] == "" {
+				blockedBy[dependent] = name
+			}
+
+			pending[dependent]--
+			if pending[dependent] > 0 {
+				continue
+			}
+
+			if upstream := blockedBy[dependent]; upstream != "" {
+				results.fail(dependent, errors.Wrapf(ErrSkipped, "promise: node %q skipped, dependency %q failed", dependent, upstream))
+				settle(dependent, true)
+				continue
+			}
+			launch(d.byName[dependent])
+		}
+	}
+
+	for _, n := range d.nodes {
+		if len(n.deps) == 0 {
+			launch(n)
+		}
+	}
+
+	for running > 0 {
+		c := <-done
+		running--
+
+		if c.err != nil {
+			results.fail(c.name, c.err)
+			failures = append(failures, c.err)
+		}
+		if c.recovered != nil && firstPanic == nil {
+			firstPanic = c.recovered
+		}
+		settle(c.name, c.err != nil)
+	}
+
+	if firstPanic != nil {
+		panic(firstPanic)
+	}
+
+	switch len(failures) {
+	case 0:
+		return results, nil
+	case 1:
+		return results, failures[0]
+	default:
+		return results, &GroupError{Errs: failures}
+	}
+}
+
+// DOT renders the graph in the Graphviz DOT language, edges go from a dependency to its
+// dependents.
+func (d *DAG) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph dag {\n")
+	for _, n := range d.nodes {
+		fmt.Fprintf(&sb, "\t%q;\n", n.name)
+	}
+	for _, n := range d.nodes {
+		for _, dependent := range n.dependents {
+			fmt.Fprintf(&sb, "\t%q -> %q;\n", n.name, dependent)
+		}
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// Results holds the outcome of the nodes of a DAG run. It is safe to read while the DAG runs.
+type Results struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+	errs   map[string]error
+}
+
+func (r *Results) set(name string, v interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.values[name] = v
+}
+
+func (r *Results) fail(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs[name] = err
+}
+
+// Err returns the error of a node, ErrSkipped for the skipped ones.
+func (r *Results) Err(name string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.errs[name]
+}
+
+// ResultOf returns the value of a node. It fails with the error of the node, or with ErrNoResult
+// when the node didn't run or its value isn't a T.
+func ResultOf[T any](r *Results, name string) (T, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var zero T
+	if err, ok := r.errs[name]; ok {
+		return zero, err
+	}
+
+	v, ok := r.values[name]
+	if !ok {
+		return zero, errors.Wrapf(ErrNoResult, "promise: ResultOf node %q", name)
+	}
+
+	typed, ok := v.(T)
+	if !ok {
+		return zero, errors.Wrapf(ErrNoResult, "promise: ResultOf node %q holds a %T", name, v)
+	}
+
+	return typed, nil
+}
@@ -0,0 +1,97 @@
+package promise
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mtavano/devkit/errors"
+)
+
+// round holds the tasks started with Go until the next Wait.
+type round[T any] struct {
+	wg         sync.WaitGroup
+	results    []Result[T]
+	failures   []error
+	firstPanic interface{}
+}
+
+// Go starts task right away as part of the group, errgroup style. It never blocks, so tasks can
+// call it to fan out recursively: Wait also waits for the tasks they start. WithConcurrency and
+// WithRateLimit still bound how many tasks run at once.
+func (g *GroupOf[T]) Go(ctx context.Context, task Task[T]) {
+	g.mu.Lock()
+	if g.round == nil {
+		g.round = &round[T]{}
+	}
+	r := g.round
+	idx := len(r.results)
+	r.results = append(r.results, Result[T]{Index: idx})
+	r.wg.Add(1)
+	g.mu.Unlock()
+
+	go func() {
+		defer r.wg.Done()
+
+		if g.sem != nil {
+			g.sem <- struct{}{}
+			defer func() { <-g.sem }()
+		}
+
+		start := time.Now()
+		res, err, panicked := runGuarded(ctx, g.opts, task)
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		result := &r.results[idx]
+		result.Duration = time.Since(start)
+		if err != nil {
+			result.Status, result.Err = Rejected, err
+			r.failures = append(r.failures, err)
+		} else {
+			result.Status, result.Value = Fulfilled, res
+		}
+		if panicked && g.opts.propagatePanics && r.firstPanic == nil {
+			r.firstPanic, _ = errors.PanicValue(err)
+		}
+	}()
+}
+
+// Wait waits for the tasks started with Go, including the ones started by other tasks, and
+// returns their results in the order they were started. Like All it returns the first error by
+// completion time, or a *GroupError when more than one task failed, but it doesn't cancel the
+// tasks. The group can be reused afterwards. Wait must not run concurrently with calls to Go
+// made from outside the group tasks.
+func (g *GroupOf[T]) Wait() ([]T, error) {
+	g.mu.Lock()
+	r := g.round
+	g.mu.Unlock()
+	if r == nil {
+		return []T{}, nil
+	}
+
+	r.wg.Wait()
+
+	g.mu.Lock()
+	g.round = nil
+	g.mu.Unlock()
+
+	if r.firstPanic != nil {
+		panic(r.firstPanic)
+	}
+
+	switch len(r.failures) {
+	case 0:
+	case 1:
+		return nil, r.failures[0]
+	default:
+		return nil, &GroupError{Errs: r.failures}
+	}
+
+	results := make([]T, len(r.results))
+	for i, result := range r.results {
+		results[i] = result.Value
+	}
+	return results, nil
+}
@@ -0,0 +1,29 @@
+package promise
+
+import (
+	"fmt"
+)
+
+// GroupError is returned when more than one task of a group failed, or by Any when every task
+// failed. Its message is the one of the first failure, and errors.Is and errors.As match any of
+// them.
+type GroupError struct {
+	// Errs are the errors of the failed tasks in completion order
+	Errs []error
+}
+
+func (e *GroupError) Error() string {
+	if len(e.Errs) == 1 {
+		return e.Errs[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e.Errs[0].Error(), len(e.Errs)-1)
+}
+
+// First returns the error of the first task that failed.
+func (e *GroupError) First() error {
+	return e.Errs[0]
+}
+
+func (e *GroupError) Unwrap() []error {
+	return e.Errs
+}
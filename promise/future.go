@@ -0,0 +1,166 @@
+package promise
+
+import (
+	"context"
+
+	"github.com/mtavano/devkit/errors"
+)
+
+// ErrChannelClosed rejects the futures of channels closed without sending a value.
+var ErrChannelClosed = errors.New("promise: channel closed without a value")
+
+// Future is the eventual result of an asynchronous task. Chained futures run once the previous
+// one settles, with the context the first one was created with.
+type Future[T any] struct {
+	ctx   context.Context
+	done  chan struct{}
+	value T
+	err   error
+}
+
+func newFuture[T any](ctx context.Context) *Future[T] {
+	return &Future[T]{ctx: ctx, done: make(chan struct{})}
+}
+
+// Async runs task in a go routine and returns the future of its result. Panics reject the future
+// with an Internal error.
+func Async[T any](ctx context.Context, task Task[T]) *Future[T] {
+	f := newFuture[T](ctx)
+	go f.settle(func() (T, error) {
+		return task(ctx)
+	})
+
+	return f
+}
+
+// Resolve returns a future already resolved to v.
+func Resolve[T any](v T) *Future[T] {
+	f := newFuture[T](context.Background())
+	f.value = v
+	close(f.done)
+
+	return f
+}
+
+// Reject returns a future already rejected with err.
+func Reject[T any](err error) *Future[T] {
+	f := newFuture[T](context.Background())
+	f.err = err
+	close(f.done)
+
+	return f
+}
+
+// FromChannel returns the future of the first value received from ch. It is rejected with
+// ErrChannelClosed when ch is closed empty, or with the context error when ctx is done first.
+func FromChannel[T any](ctx context.Context, ch <-chan T) *Future[T] {
+	return Async(ctx, func(ctx context.Context) (T, error) {
+		var zero T
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return zero, ErrChannelClosed
+			}
+			return v, nil
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	})
+}
+
+// settle stores the result of fn and releases the waiters.
+func (f *Future[T]) settle(fn func() (T, error)) {
+	defer close(f.done)
+	f.value, f.err = call(fn)
+}
+
+// call runs fn returning its panic as an error.
+func call[T any](fn func() (T, error)) (res T, err error) {
+	defer errors.Recover(&err)
+	return fn()
+}
+
+// Done is closed once the future settles.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await waits for the future to settle and returns its result, or the context error when ctx is
+// done first. The task keeps running in the latter case.
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Channel returns a channel receiving the result once the future settles, closed afterwards.
+func (f *Future[T]) Channel() <-chan Result[T] {
+	ch := make(chan Result[T], 1)
+	go func() {
+		defer close(ch)
+		<-f.done
+
+		result := Result[T]{Status: Fulfilled, Value: f.value}
+		if f.err != nil {
+			result.Status, result.Err = Rejected, f.err
+		}
+		ch <- result
+	}()
+
+	return ch
+}
+
+// Catch returns a future recovering from the rejection of f with fn. Resolved values pass
+// through.
+func (f *Future[T]) Catch(fn func(ctx context.Context, err error) (T, error)) *Future[T] {
+	next := newFuture[T](f.ctx)
+	go next.settle(func() (T, error) {
+		<-f.done
+		if f.err == nil {
+			return f.value, nil
+		}
+		return fn(f.ctx, f.err)
+	})
+
+	return next
+}
+
+// Finally returns a future settling like f once fn ran, whatever the outcome of f.
+func (f *Future[T]) Finally(fn func()) *Future[T] {
+	next := newFuture[T](f.ctx)
+	go next.settle(func() (T, error) {
+		<-f.done
+		fn()
+		return f.value, f.err
+	})
+
+	return next
+}
+
+// Then returns the future of fn applied to the value of f. Rejections pass through without
+// calling fn.
+func Then[T, U any](f *Future[T], fn func(ctx context.Context, v T) (U, error)) *Future[U] {
+	next := newFuture[U](f.ctx)
+	go next.settle(func() (U, error) {
+		<-f.done
+		if f.err != nil {
+			var zero U
+			return zero, f.err
+		}
+		return fn(f.ctx, f.value)
+	})
+
+	return next
+}
+
+// ThenFuture is Then for transforms that return a future themselves, the returned future settles
+// like the one of fn.
+func ThenFuture[T, U any](f *Future[T], fn func(ctx context.Context, v T) *Future[U]) *Future[U] {
+	return Then(f, func(ctx context.Context, v T) (U, error) {
+		return fn(ctx, v).Await(ctx)
+	})
+}
@@ -0,0 +1,237 @@
+package promise
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mtavano/devkit/errors"
+)
+
+// Task is a typed unit of work executed into a go routine. The context is the one given to the
+// group.
+type Task[T any] func(ctx context.Context) (T, error)
+
+// GroupOf runs typed tasks concurrently, returning their results in the order of the tasks. Its
+// tasks are given at construction, or added while it runs with Go.
+type GroupOf[T any] struct {
+	tasks []Task[T]
+	opts  options
+
+	// the state of the tasks started with Go
+	mu    sync.Mutex
+	round *round[T]
+	sem   chan struct{}
+}
+
+func NewGroupOf[T any](tasks []Task[T], opts ...Option) *GroupOf[T] {
+	g := &GroupOf[T]{
+		tasks: tasks,
+		opts:  newOptions(opts),
+	}
+	if g.opts.concurrency > 0 {
+		g.sem = make(chan struct{}, g.opts.concurrency)
+	}
+
+	return g
+}
+
+// ExecAll executes every task no matter if any fails or not. The results and errors are indexed
+// like the tasks, failed tasks leave the zero value as result, and the flag reports whether any
+// task failed.
+func (g *GroupOf[T]) ExecAll(ctx context.Context) ([]T, []error, bool) {
+	results, errs, failures := g.run(ctx, func() {})
+	return results, errs, len(failures) > 0
+}
+
+// All executes every task and returns their results. On the first failure the context given to
+// the tasks is cancelled and tasks that didn't start yet are skipped. All still waits for the
+// running tasks, then returns the first error by completion time, or a *GroupError when other
+// tasks failed too. Errors caused by that cancellation aren't counted as failures.
+func (g *GroupOf[T]) All(ctx context.Context) ([]T, error) {
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	results, _, failures := g.run(ctx, cancel)
+	if len(failures) > 1 && parent.Err() == nil {
+		failures = withoutCancellations(failures)
+	}
+
+	switch len(failures) {
+	case 0:
+		return results, nil
+	case 1:
+		return nil, failures[0]
+	default:
+		return nil, &GroupError{Errs: failures}
+	}
+}
+
+// run executes the tasks and waits for all of them. Results and errors are indexed like the tasks,
+// failures are also collected in completion order and onFailure is called on each of them.
+func (g *GroupOf[T]) run(ctx context.Context, onFailure func()) ([]T, []error, []error) {
+	results := make([]T, len(g.tasks))
+	errs := make([]error, len(g.tasks))
+
+	var mu sync.Mutex
+	failures := []error{}
+
+	recovered := g.execute(ctx, func(r Result[T]) {
+		if r.Err != nil {
+			errs[r.Index] = r.Err
+
+			mu.Lock()
+			failures = append(failures, r.Err)
+			mu.Unlock()
+
+			onFailure()
+			return
+		}
+		results[r.Index] = r.Value
+	})
+	if recovered != nil {
+		panic(recovered)
+	}
+
+	return results, errs, failures
+}
+
+// execute runs the tasks, each in its own go routine or through the worker pool set with
+// WithConcurrency, and waits for all of them. emit is called concurrently with the result of
+// every task as it completes. Panics are turned into errors, with WithPanicPropagation the
+// first panic value is also returned for the caller to panic again.
+func (g *GroupOf[T]) execute(ctx context.Context, emit func(Result[T])) interface{} {
+	var mu sync.Mutex
+	var firstPanic interface{}
+
+	exec := func(idx int) {
+		start := time.Now()
+		res, err, panicked := runGuarded(ctx, g.opts, g.tasks[idx])
+
+		result := Result[T]{Index: idx, Status: Fulfilled, Value: res, Duration: time.Since(start)}
+		if err != nil {
+			var zero T
+			result.Status, result.Value, result.Err = Rejected, zero, err
+		}
+		if panicked && g.opts.propagatePanics {
+			result.recovered, _ = errors.PanicValue(err)
+
+			mu.Lock()
+			if firstPanic == nil {
+				firstPanic = result.recovered
+			}
+			mu.Unlock()
+		}
+		emit(result)
+	}
+
+	workers := len(g.tasks)
+	if g.opts.concurrency > 0 && g.opts.concurrency < workers {
+		workers = g.opts.concurrency
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				exec(idx)
+			}
+		}()
+	}
+
+	for idx := range g.tasks {
+		indexes <- idx
+	}
+	close(indexes)
+	wg.Wait()
+
+	return firstPanic
+}
+
+// withoutCancellations drops the errors after the first one caused by the group cancelling its
+// own context, they are consequences of the first failure and not failures of their own.
+func withoutCancellations(failures []error) []error {
+	kept := failures[:1]
+	for _, err := range failures[1:] {
+		if !errors.Is(err, context.Canceled) {
+			kept = append(kept, err)
+		}
+	}
+
+	return kept
+}
+
+// runGuarded calls fn unless ctx is already done, after waiting for the rate limiter of opts. It
+// is how groups and DAGs run their work: a panic of fn is returned as an error carrying the panic
+// value and stack, and reported by panicked.
+func runGuarded[T any](ctx context.Context, opts options, fn func(ctx context.Context) (T, error)) (res T, err error, panicked bool) {
+	if err := ctx.Err(); err != nil {
+		return res, err, false
+	}
+
+	if opts.limiter != nil {
+		if err := opts.limiter.Wait(ctx); err != nil {
+			return res, err, false
+		}
+	}
+
+	// panicked stays set when fn doesn't return
+	defer errors.Recover(&err)
+	panicked = true
+	res, err = fn(ctx)
+	return res, err, false
+}
+
+// All2 runs two tasks of different types concurrently and returns both results, or the error of
+// the first failing task.
+func All2[A, B any](ctx context.Context, taskA Task[A], taskB Task[B]) (A, B, error) {
+	var a A
+	var b B
+
+	_, err := NewGroupOf([]Task[struct{}]{
+		into(taskA, &a),
+		into(taskB, &b),
+	}).All(ctx)
+	if err != nil {
+		var zeroA A
+		var zeroB B
+		return zeroA, zeroB, err
+	}
+
+	return a, b, nil
+}
+
+// All3 runs three tasks of different types concurrently and returns their results, or the error
+// of the first failing task.
+func All3[A, B, C any](ctx context.Context, taskA Task[A], taskB Task[B], taskC Task[C]) (A, B, C, error) {
+	var a A
+	var b B
+	var c C
+
+	_, err := NewGroupOf([]Task[struct{}]{
+		into(taskA, &a),
+		into(taskB, &b),
+		into(taskC, &c),
+	}).All(ctx)
+	if err != nil {
+		var zeroA A
+		var zeroB B
+		var zeroC C
+		return zeroA, zeroB, zeroC, err
+	}
+
+	return a, b, c, nil
+}
+
+// into adapts a task to run in a group of another type, storing its result in dst.
+func into[T any](task Task[T], dst *T) Task[struct{}] {
+	return func(ctx context.Context) (struct{}, error) {
+		res, err := task(ctx)
+		*dst = res
+		return struct{}{}, err
+	}
+}
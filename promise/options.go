@@ -0,0 +1,48 @@
+package promise
+
+import (
+	"golang.org/x/time/rate"
+)
+
+// Option configures how a group runs its tasks.
+type Option func(*options)
+
+type options struct {
+	concurrency     int
+	limiter         *rate.Limiter
+	propagatePanics bool
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithConcurrency runs the tasks through a pool of n workers instead of a go routine per task.
+// Results keep the order of the tasks. Values below 1 mean no limit.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithRateLimit makes every task wait for the limiter before starting, so a group can pace the
+// calls it fans out.
+func WithRateLimit(limiter *rate.Limiter) Option {
+	return func(o *options) {
+		o.limiter = limiter
+	}
+}
+
+// WithPanicPropagation makes the group panic again in the caller with the value of the first task
+// that panicked, once the tasks finished. By default panics are returned as Internal errors in the
+// slot of the task.
+func WithPanicPropagation() Option {
+	return func(o *options) {
+		o.propagatePanics = true
+	}
+}
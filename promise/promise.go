@@ -1,7 +1,7 @@
 package promise
 
 import (
-	"sync"
+	"context"
 )
 
 // Func represents a function that will be executed into a go routine.
@@ -10,19 +10,27 @@ type Func func() (interface{}, error)
 // ErrorHandler is the custom error handler for manage error on our Promise funcs.
 type ErrorHandler func(err error) error
 
-// Group is the structure that holds the Promises and handles the syncronization between them
+// Group is the structure that holds the Promises and handles the syncronization between them.
+// It is the untyped version of GroupOf, prefer GroupOf to avoid asserting the results.
 type Group struct {
-	tasks []Func
-	wg    *sync.WaitGroup
+	group *GroupOf[interface{}]
 }
 
-func NewGroup(tasks []Func) *Group {
-	wg := new(sync.WaitGroup)
-	wg.Add(len(tasks))
+func NewGroup(tasks []Func, opts ...Option) *Group {
+	typed := make([]Task[interface{}], 0, len(tasks))
+	for _, fn := range tasks {
+		typed = append(typed, task(fn))
+	}
 
 	return &Group{
-		tasks: tasks,
-		wg:    wg,
+		group: NewGroupOf(typed, opts...),
+	}
+}
+
+// task adapts a promise Func to a Task.
+func task(fn Func) Task[interface{}] {
+	return func(context.Context) (interface{}, error) {
+		return fn()
 	}
 }
 
@@ -30,56 +38,27 @@ func NewGroup(tasks []Func) *Group {
 // of interfaces that must be casted in order to properly handle the desired returned data by
 // the defined promise Func
 func (g *Group) ExecAll() ([]interface{}, []error, bool) {
-	results := make([]interface{}, len(g.tasks), len(g.tasks))
-	errs := make([]error, len(g.tasks), len(g.tasks))
-	hasError := false
-
-	for idx, fn := range g.tasks {
-		go func(idx int, fn Func) {
-			defer g.wg.Done()
-
-			res, err := fn()
-			if err != nil {
-				hasError = true
-				errs[idx] = err
-				return
-			}
-
-			results[idx] = res
-		}(idx, fn)
-	}
-
-	g.wg.Wait()
-	return results, errs, hasError
+	return g.group.ExecAll(context.Background())
 }
 
 // All will run the promise Func into the slice but if any fails, will skip the subsequent funcs
-// and will return the error returned by the promise Fun
-func (g *Group) All() (_ []interface{}, err error) {
-	results := make([]interface{}, len(g.tasks), len(g.tasks))
-
-	for idx, fn := range g.tasks {
-		go func(idx int, fn Func) {
-			defer g.wg.Done()
-
-			if err != nil {
-				return
-			}
-
-			res, e := fn()
-			if e != nil {
-				err = e
-				return
-			}
+// and will return the error returned by the promise Func, see GroupOf.All
+func (g *Group) All() ([]interface{}, error) {
+	return g.AllContext(context.Background())
+}
 
-			results[idx] = res
-		}(idx, fn)
-	}
-	g.wg.Wait()
+// AllContext is All bound to ctx. Funcs don't receive the context, so only the ones that didn't
+// start yet are skipped when it is cancelled.
+func (g *Group) AllContext(ctx context.Context) ([]interface{}, error) {
+	return g.group.All(ctx)
+}
 
-	if err != nil {
-		return nil, err
-	}
+// Go starts fn right away as part of the group, see GroupOf.Go.
+func (g *Group) Go(fn Func) {
+	g.group.Go(context.Background(), task(fn))
+}
 
-	return results, nil
+// Wait waits for the funcs started with Go and returns their results, see GroupOf.Wait.
+func (g *Group) Wait() ([]interface{}, error) {
+	return g.group.Wait()
 }
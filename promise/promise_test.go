@@ -0,0 +1,824 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	deverrors "github.com/mtavano/devkit/errors"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+var errTask = errors.New("task failed")
+
+func value[T any](v T) Task[T] {
+	return func(context.Context) (T, error) {
+		return v, nil
+	}
+}
+
+func failure[T any](err error) Task[T] {
+	return func(context.Context) (T, error) {
+		var zero T
+		return zero, err
+	}
+}
+
+func Test_Group(t *testing.T) {
+	testCases := []struct {
+		name            string
+		tasks           []Func
+		expectedResults []interface{}
+		expectedErr     error
+	}{
+		{
+			name: "should return the results in order",
+			tasks: []Func{
+				func() (interface{}, error) { return 1, nil },
+				func() (interface{}, error) { return "two", nil },
+			},
+			expectedResults: []interface{}{1, "two"},
+		},
+		{
+			name: "should return the error",
+			tasks: []Func{
+				func() (interface{}, error) { return 1, nil },
+				func() (interface{}, error) { return nil, errTask },
+			},
+			expectedErr: errTask,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := NewGroup(tc.tasks).All()
+			require.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr == nil {
+				require.Equal(t, tc.expectedResults, results)
+			}
+
+			results, errs, hasError := NewGroup(tc.tasks).ExecAll()
+			require.Equal(t, tc.expectedErr != nil, hasError)
+			require.Len(t, results, len(tc.tasks))
+			require.Len(t, errs, len(tc.tasks))
+		})
+	}
+}
+
+func Test_GroupOf(t *testing.T) {
+	tasks := []Task[string]{}
+	for i := 0; i < 10; i++ {
+		tasks = append(tasks, value(strconv.Itoa(i)))
+	}
+
+	results, err := NewGroupOf(tasks).All(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}, results)
+
+	tasks[3] = failure[string](errTask)
+	results, errs, hasError := NewGroupOf(tasks).ExecAll(context.Background())
+	require.True(t, hasError)
+	require.Equal(t, "", results[3])
+	require.Equal(t, "4", results[4])
+	require.Equal(t, errTask, errs[3])
+	require.Nil(t, errs[4])
+}
+
+func Test_All2(t *testing.T) {
+	n, s, err := All2(context.Background(), value(42), value("vault"))
+	require.NoError(t, err)
+	require.Equal(t, 42, n)
+	require.Equal(t, "vault", s)
+
+	n, s, err = All2(context.Background(), value(42), failure[string](errTask))
+	require.Equal(t, errTask, err)
+	require.Equal(t, 0, n)
+	require.Equal(t, "", s)
+}
+
+func Test_All3(t *testing.T) {
+	n, s, b, err := All3(context.Background(), value(42), value("vault"), value(true))
+	require.NoError(t, err)
+	require.Equal(t, 42, n)
+	require.Equal(t, "vault", s)
+	require.True(t, b)
+
+	_, _, _, err = All3(context.Background(), value(42), value("vault"), failure[bool](errTask))
+	require.Equal(t, errTask, err)
+}
+
+func Test_GroupOf_All_FailFast(t *testing.T) {
+	errSlow := errors.New("slow task failed")
+
+	t.Run("should cancel the running tasks", func(t *testing.T) {
+		started := make(chan struct{})
+		cancelled := false
+		results, err := NewGroupOf([]Task[int]{
+			func(ctx context.Context) (int, error) {
+				close(started)
+				<-ctx.Done()
+				cancelled = true
+				return 0, ctx.Err()
+			},
+			func(ctx context.Context) (int, error) {
+				<-started
+				return 0, errTask
+			},
+		}).All(context.Background())
+
+		require.True(t, cancelled)
+		require.Nil(t, results)
+		require.Equal(t, errTask, err)
+	})
+
+	t.Run("should return the first error by completion time", func(t *testing.T) {
+		started := make(chan struct{})
+		_, err := NewGroupOf([]Task[int]{
+			func(ctx context.Context) (int, error) {
+				close(started)
+				<-ctx.Done()
+				return 0, errSlow
+			},
+			func(ctx context.Context) (int, error) {
+				<-started
+				return 0, errTask
+			},
+		}).All(context.Background())
+
+		var groupErr *GroupError
+		require.ErrorAs(t, err, &groupErr)
+		require.Equal(t, errTask, groupErr.First())
+		require.Equal(t, []error{errTask, errSlow}, groupErr.Errs)
+		require.ErrorIs(t, err, errSlow)
+		require.Equal(t, "task failed (and 1 more errors)", err.Error())
+	})
+
+	t.Run("should return a single failure as it is", func(t *testing.T) {
+		_, err := NewGroupOf([]Task[int]{value(1), failure[int](errTask), value(3)}).All(context.Background())
+		require.Equal(t, errTask, err)
+	})
+
+	t.Run("should skip tasks once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewGroup([]Func{
+			func() (interface{}, error) {
+				t.Fatal("the func must not run")
+				return nil, nil
+			},
+		}).AllContext(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func Test_GroupOf_Options(t *testing.T) {
+	t.Run("should bound the concurrency", func(t *testing.T) {
+		var running, maxRunning int32
+		tasks := []Task[int]{}
+		for i := 0; i < 50; i++ {
+			i := i
+			tasks = append(tasks, func(ctx context.Context) (int, error) {
+				current := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					observed := atomic.LoadInt32(&maxRunning)
+					if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+						break
+					}
+				}
+
+				time.Sleep(time.Millisecond)
+				return i, nil
+			})
+		}
+
+		results, err := NewGroupOf(tasks, WithConcurrency(4)).All(context.Background())
+		require.NoError(t, err)
+		require.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(4))
+		for i, res := range results {
+			require.Equal(t, i, res)
+		}
+	})
+
+	t.Run("should pace the tasks", func(t *testing.T) {
+		tasks := []Func{}
+		for i := 0; i < 5; i++ {
+			tasks = append(tasks, func() (interface{}, error) { return nil, nil })
+		}
+
+		start := time.Now()
+		_, err := NewGroup(tasks, WithRateLimit(rate.NewLimiter(rate.Every(10*time.Millisecond), 1))).All()
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("should stop waiting for the limiter on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+		_, errs, hasError := NewGroupOf([]Task[int]{value(1), value(2)}, WithRateLimit(limiter)).ExecAll(ctx)
+		require.True(t, hasError)
+		require.Len(t, errs, 2)
+	})
+}
+
+// blocked waits for the cancellation of its context.
+func blocked[T any]() Task[T] {
+	return func(ctx context.Context) (T, error) {
+		<-ctx.Done()
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+func Test_GroupOf_Race(t *testing.T) {
+	testCases := []struct {
+		name          string
+		tasks         []Task[string]
+		expectedValue string
+		expectedErr   error
+	}{
+		{name: "should return the first success", tasks: []Task[string]{blocked[string](), value("fast")}, expectedValue: "fast"},
+		{name: "should return the first failure", tasks: []Task[string]{blocked[string](), failure[string](errTask)}, expectedErr: errTask},
+		{name: "should fail without tasks", expectedErr: ErrNoTasks},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewGroupOf(tc.tasks).Race(context.Background())
+			require.Equal(t, tc.expectedErr, err)
+			require.Equal(t, tc.expectedValue, v)
+		})
+	}
+}
+
+func Test_GroupOf_Any(t *testing.T) {
+	errOther := errors.New("other task failed")
+
+	v, err := NewGroupOf([]Task[string]{failure[string](errTask), blocked[string](), value("vault 7")}).Any(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "vault 7", v)
+
+	_, err = NewGroupOf([]Task[string]{failure[string](errTask), failure[string](errOther)}).Any(context.Background())
+	var groupErr *GroupError
+	require.ErrorAs(t, err, &groupErr)
+	require.Len(t, groupErr.Errs, 2)
+	require.ErrorIs(t, err, errTask)
+	require.ErrorIs(t, err, errOther)
+
+	_, err = NewGroupOf([]Task[string]{}).Any(context.Background())
+	require.Equal(t, ErrNoTasks, err)
+}
+
+func Test_GroupOf_AllSettled(t *testing.T) {
+	results := NewGroupOf([]Task[int]{value(1), failure[int](errTask), value(3)}, WithConcurrency(1)).AllSettled(context.Background())
+	require.Len(t, results, 3)
+
+	require.Equal(t, Fulfilled, results[0].Status)
+	require.Equal(t, 1, results[0].Value)
+	require.Equal(t, 1, results[1].Index)
+	require.Equal(t, Rejected, results[1].Status)
+	require.Equal(t, errTask, results[1].Err)
+	require.Equal(t, "rejected", results[1].Status.String())
+	require.Equal(t, 3, results[2].Value)
+}
+
+func Test_Task_Options(t *testing.T) {
+	errUnavailable := deverrors.WithKind(deverrors.New("fireblocks: vault unavailable"), deverrors.Unavailable)
+
+	// flaky fails with err the first failures attempts
+	flaky := func(failures int, err error) (Task[string], *int32) {
+		var attempts int32
+		return func(ctx context.Context) (string, error) {
+			if atomic.AddInt32(&attempts, 1) <= int32(failures) {
+				return "", err
+			}
+			return "balance", nil
+		}, &attempts
+	}
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	testCases := []struct {
+		name             string
+		task             func() (Task[string], *int32)
+		expectedValue    string
+		expectedKind     deverrors.Kind
+		expectedErr      bool
+		expectedAttempts int32
+	}{
+		{
+			name: "should retry retryable errors",
+			task: func() (Task[string], *int32) {
+				task, attempts := flaky(2, errUnavailable)
+				return task.WithRetry(policy), attempts
+			},
+			expectedValue:    "balance",
+			expectedAttempts: 3,
+		},
+		{
+			name: "should give up after the max attempts",
+			task: func() (Task[string], *int32) {
+				task, attempts := flaky(5, errUnavailable)
+				return task.WithRetry(policy), attempts
+			},
+			expectedErr:      true,
+			expectedKind:     deverrors.Unavailable,
+			expectedAttempts: 3,
+		},
+		{
+			name: "should not retry other errors",
+			task: func() (Task[string], *int32) {
+				task, attempts := flaky(5, errTask)
+				return task.WithRetry(policy), attempts
+			},
+			expectedErr:      true,
+			expectedAttempts: 1,
+		},
+		{
+			name: "should use the fallback on final failure",
+			task: func() (Task[string], *int32) {
+				task, attempts := flaky(5, errUnavailable)
+				return task.WithRetry(policy).WithFallback("cached balance"), attempts
+			},
+			expectedValue:    "cached balance",
+			expectedAttempts: 3,
+		},
+		{
+			name: "should time out slow attempts",
+			task: func() (Task[string], *int32) {
+				var attempts int32
+				task := Task[string](func(ctx context.Context) (string, error) {
+					atomic.AddInt32(&attempts, 1)
+					<-ctx.Done()
+					return "", ctx.Err()
+				})
+				return task.WithTimeout(time.Millisecond).WithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}), &attempts
+			},
+			expectedErr:      true,
+			expectedKind:     deverrors.Timeout,
+			expectedAttempts: 2,
+		},
+		{
+			name: "should pass the error to the fallback func",
+			task: func() (Task[string], *int32) {
+				task, attempts := flaky(1, errTask)
+				return task.WithFallbackFunc(func(ctx context.Context, err error) (string, error) {
+					return "fallback: " + err.Error(), nil
+				}), attempts
+			},
+			expectedValue:    "fallback: task failed",
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task, attempts := tc.task()
+
+			results, errs, _ := NewGroupOf([]Task[string]{task}).ExecAll(context.Background())
+			require.Equal(t, tc.expectedAttempts, atomic.LoadInt32(attempts))
+			require.Equal(t, tc.expectedValue, results[0])
+			require.Equal(t, tc.expectedErr, errs[0] != nil)
+			if tc.expectedKind != deverrors.Unknown {
+				require.Equal(t, tc.expectedKind, deverrors.KindOf(errs[0]))
+			}
+		})
+	}
+}
+
+func panicking(ctx context.Context) (int, error) {
+	var balances map[string]int
+	balances["vault 7"] = 10
+	return 0, nil
+}
+
+func Test_GroupOf_Panics(t *testing.T) {
+	t.Run("should return panics as errors", func(t *testing.T) {
+		results, errs, hasError := NewGroupOf([]Task[int]{value(1), panicking}).ExecAll(context.Background())
+		require.True(t, hasError)
+		require.Equal(t, 1, results[0])
+		require.Equal(t, deverrors.Internal, deverrors.KindOf(errs[1]))
+
+		recovered, ok := deverrors.PanicValue(errs[1])
+		require.True(t, ok)
+		require.Contains(t, fmt.Sprint(recovered), "assignment to entry in nil map")
+
+		trace := deverrors.GetCauseFromError(errs[1]).Trace
+		require.NotEmpty(t, trace)
+		require.Equal(t, "github.com/mtavano/devkit/promise.panicking", trace[0].Function)
+	})
+
+	t.Run("should fail fast on panics", func(t *testing.T) {
+		_, err := NewGroup([]Func{
+			func() (interface{}, error) { panic("vault locked") },
+		}).All()
+		require.Equal(t, "panic: vault locked", err.Error())
+	})
+
+	t.Run("should panic again in the caller when propagating", func(t *testing.T) {
+		require.PanicsWithValue(t, "vault locked", func() {
+			_, _ = NewGroupOf([]Task[int]{
+				value(1),
+				func(ctx context.Context) (int, error) { panic("vault locked") },
+			}, WithPanicPropagation()).All(context.Background())
+		})
+
+		require.PanicsWithValue(t, "vault locked", func() {
+			_, _ = NewGroupOf([]Task[int]{
+				func(ctx context.Context) (int, error) { panic("vault locked") },
+			}, WithPanicPropagation()).Race(context.Background())
+		})
+	})
+}
+
+func Test_GroupOf_Go(t *testing.T) {
+	t.Run("should wait for tasks started by tasks", func(t *testing.T) {
+		g := NewGroupOf[int](nil, WithConcurrency(2))
+
+		// walks 5 pages, each one starting the fetch of the next
+		var fetch func(page int) Task[int]
+		fetch = func(page int) Task[int] {
+			return func(ctx context.Context) (int, error) {
+				if page < 5 {
+					g.Go(ctx, fetch(page+1))
+				}
+				return page, nil
+			}
+		}
+
+		g.Go(context.Background(), fetch(1))
+		results, err := g.Wait()
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2, 3, 4, 5}, results)
+
+		// the group is reusable
+		g.Go(context.Background(), value(10))
+		g.Go(context.Background(), failure[int](errTask))
+		_, err = g.Wait()
+		require.Equal(t, errTask, err)
+
+		results, err = g.Wait()
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+
+	t.Run("should run untyped funcs", func(t *testing.T) {
+		g := NewGroup(nil)
+		g.Go(func() (interface{}, error) { return "vault 7", nil })
+		g.Go(func() (interface{}, error) { return 7, nil })
+
+		results, err := g.Wait()
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{"vault 7", 7}, results)
+	})
+}
+
+func Test_Future(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should chain transforms", func(t *testing.T) {
+		// create a refresh intent, poll it and fetch the movements
+		intent := Async(ctx, value("ri_123"))
+		polled := ThenFuture(intent, func(ctx context.Context, id string) *Future[bool] {
+			return Async(ctx, func(context.Context) (bool, error) { return id == "ri_123", nil })
+		})
+		movements := Then(polled, func(ctx context.Context, ready bool) ([]string, error) {
+			if !ready {
+				return nil, errTask
+			}
+			return []string{"mov_1", "mov_2"}, nil
+		})
+
+		v, err := movements.Await(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"mov_1", "mov_2"}, v)
+	})
+
+	t.Run("should skip transforms on rejection and recover with catch", func(t *testing.T) {
+		// the callbacks run in other go routines, they are checked once the future settled
+		var called, finally int32
+		var caught error
+		f := Then(Reject[int](errTask), func(ctx context.Context, v int) (int, error) {
+			atomic.StoreInt32(&called, 1)
+			return v, nil
+		}).Catch(func(ctx context.Context, err error) (int, error) {
+			caught = err
+			return 7, nil
+		}).Finally(func() {
+			atomic.StoreInt32(&finally, 1)
+		})
+
+		v, err := f.Await(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 7, v)
+		require.Equal(t, errTask, caught)
+		require.Equal(t, int32(0), atomic.LoadInt32(&called))
+		require.Equal(t, int32(1), atomic.LoadInt32(&finally))
+	})
+
+	t.Run("should reject on panics", func(t *testing.T) {
+		_, err := Async(ctx, panicking).Await(ctx)
+		require.Equal(t, deverrors.Internal, deverrors.KindOf(err))
+	})
+
+	t.Run("should stop awaiting when the context is done", func(t *testing.T) {
+		awaitCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := Async(awaitCtx, blocked[int]()).Await(awaitCtx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should convert from and to channels", func(t *testing.T) {
+		ch := make(chan int, 1)
+		ch <- 42
+
+		result := <-FromChannel(ctx, ch).Channel()
+		require.Equal(t, Fulfilled, result.Status)
+		require.Equal(t, 42, result.Value)
+
+		close(ch)
+		_, err := FromChannel(ctx, ch).Await(ctx)
+		require.Equal(t, ErrChannelClosed, err)
+
+		v, err := Resolve("done").Await(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "done", v)
+	})
+}
+
+func constant[T any](v T) NodeFunc[T] {
+	return func(context.Context, *Results) (T, error) {
+		return v, nil
+	}
+}
+
+func Test_DAG(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should run the nodes after their dependencies", func(t *testing.T) {
+		b := NewDAG(WithConcurrency(2))
+		AddNode(b, "accounts", func(context.Context, *Results) ([]string, error) {
+			return []string{"acc_1", "acc_2"}, nil
+		})
+		AddNode(b, "balances", func(context.Context, *Results) (int, error) {
+			return 100, nil
+		})
+		AddNode(b, "movements", func(ctx context.Context, results *Results) (int, error) {
+			accounts, err := ResultOf[[]string](results, "accounts")
+			if err != nil {
+				return 0, err
+			}
+			return len(accounts) * 10, nil
+		}, "accounts")
+		AddNode(b, "report", func(ctx context.Context, results *Results) (string, error) {
+			movements, _ := ResultOf[int](results, "movements")
+			balances, _ := ResultOf[int](results, "balances")
+			return fmt.Sprintf("%d/%d", movements, balances), nil
+		}, "movements", "balances")
+
+		dag, err := b.Build()
+		require.NoError(t, err)
+
+		results, err := dag.Run(ctx)
+		require.NoError(t, err)
+
+		report, err := ResultOf[string](results, "report")
+		require.NoError(t, err)
+		require.Equal(t, "20/100", report)
+
+		_, err = ResultOf[int](results, "report")
+		require.ErrorIs(t, err, ErrNoResult)
+		_, err = ResultOf[int](results, "unknown")
+		require.ErrorIs(t, err, ErrNoResult)
+	})
+
+	t.Run("should skip the dependents of failed nodes", func(t *testing.T) {
+		var ran int32
+		count := func(context.Context, *Results) (int, error) {
+			atomic.AddInt32(&ran, 1)
+			return 1, nil
+		}
+
+		b := NewDAG()
+		AddNode(b, "accounts", func(context.Context, *Results) (int, error) { return 0, errTask })
+		AddNode(b, "movements", count, "accounts")
+		AddNode(b, "report", count, "movements", "balances")
+		AddNode(b, "balances", count)
+
+		dag, err := b.Build()
+		require.NoError(t, err)
+
+		results, err := dag.Run(ctx)
+		require.Equal(t, errTask, err)
+		require.Equal(t, int32(1), atomic.LoadInt32(&ran))
+		require.Equal(t, errTask, results.Err("accounts"))
+		require.ErrorIs(t, results.Err("movements"), ErrSkipped)
+		require.ErrorIs(t, results.Err("report"), ErrSkipped)
+
+		balances, err := ResultOf[int](results, "balances")
+		require.NoError(t, err)
+		require.Equal(t, 1, balances)
+	})
+
+	t.Run("should fail nodes that panic", func(t *testing.T) {
+		b := NewDAG()
+		AddNode(b, "accounts", func(ctx context.Context, _ *Results) (int, error) { return panicking(ctx) })
+
+		dag, err := b.Build()
+		require.NoError(t, err)
+
+		_, err = dag.Run(ctx)
+		require.Equal(t, deverrors.Internal, deverrors.KindOf(err))
+	})
+
+	t.Run("should propagate panics once the nodes settled", func(t *testing.T) {
+		var ran int32
+		b := NewDAG(WithPanicPropagation())
+		AddNode(b, "accounts", func(ctx context.Context, _ *Results) (int, error) { return panicking(ctx) })
+		AddNode(b, "balances", func(context.Context, *Results) (int, error) {
+			atomic.AddInt32(&ran, 1)
+			return 1, nil
+		})
+
+		dag, err := b.Build()
+		require.NoError(t, err)
+
+		require.Panics(t, func() { _, _ = dag.Run(ctx) })
+		require.Equal(t, int32(1), atomic.LoadInt32(&ran))
+	})
+
+	t.Run("should count repeated dependencies once", func(t *testing.T) {
+		b := NewDAG()
+		AddNode(b, "accounts", constant(1))
+		AddNode(b, "movements", constant(2), "accounts", "accounts")
+
+		dag, err := b.Build()
+		require.NoError(t, err)
+
+		results, err := dag.Run(ctx)
+		require.NoError(t, err)
+
+		movements, err := ResultOf[int](results, "movements")
+		require.NoError(t, err)
+		require.Equal(t, 2, movements)
+		require.Equal(t, 1, strings.Count(dag.DOT(), "->"))
+	})
+
+	t.Run("should export the graph as dot", func(t *testing.T) {
+		b := NewDAG()
+		AddNode(b, "accounts", constant(1))
+		AddNode(b, "movements", constant(2), "accounts")
+
+		dag, err := b.Build()
+		require.NoError(t, err)
+		require.Equal(t, "digraph dag {\n\t\"accounts\";\n\t\"movements\";\n\t\"accounts\" -> \"movements\";\n}\n", dag.DOT())
+	})
+}
+
+func Test_DAGBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		name        string
+		build       func(b *DAGBuilder)
+		expectedErr string
+	}{
+		{
+			name: "should fail on duplicated nodes",
+			build: func(b *DAGBuilder) {
+				AddNode(b, "accounts", constant(1))
+				AddNode(b, "accounts", constant(1))
+			},
+			expectedErr: `node "accounts" declared twice`,
+		},
+		{
+			name: "should fail on unknown dependencies",
+			build: func(b *DAGBuilder) {
+				AddNode(b, "movements", constant(1), "accounts")
+			},
+			expectedErr: `node "movements" depends on unknown node "accounts"`,
+		},
+		{
+			name: "should fail on cycles",
+			build: func(b *DAGBuilder) {
+				AddNode(b, "accounts", constant(1), "report")
+				AddNode(b, "movements", constant(1), "accounts")
+				AddNode(b, "report", constant(1), "movements")
+			},
+			expectedErr: "cycle accounts -> report -> movements -> accounts",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewDAG()
+			tc.build(b)
+
+			_, err := b.Build()
+			require.ErrorIs(t, err, ErrInvalidDAG)
+			require.Equal(t, deverrors.Invalid, deverrors.KindOf(err))
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func Test_GroupOf_Stream(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should send the results in completion order", func(t *testing.T) {
+		release := make(chan struct{})
+		stream := NewGroupOf([]Task[int]{
+			func(context.Context) (int, error) {
+				<-release
+				return 1, nil
+			},
+			failure[int](errTask),
+		}).Stream(ctx, 0)
+
+		first := <-stream.Results()
+		require.Equal(t, 1, first.Index)
+		require.Equal(t, errTask, first.Err)
+
+		close(release)
+		second, ok := stream.Next()
+		require.True(t, ok)
+		require.Equal(t, 0, second.Index)
+		require.Equal(t, 1, second.Value)
+
+		_, ok = stream.Next()
+		require.False(t, ok)
+	})
+
+	t.Run("should block the tasks past the buffer", func(t *testing.T) {
+		var started int32
+		starts := make(chan int32, 5)
+		tasks := []Task[int]{}
+		for i := 0; i < 5; i++ {
+			tasks = append(tasks, func(context.Context) (int, error) {
+				n := atomic.AddInt32(&started, 1)
+				starts <- n
+				return int(n), nil
+			})
+		}
+
+		stream := NewGroupOf(tasks, WithConcurrency(1)).Stream(ctx, 2)
+
+		// the third task starts once the first two results are queued, then waits for room to send
+		// its own, so the fourth can't start until a result is received
+		require.Equal(t, int32(1), <-starts)
+		require.Equal(t, int32(2), <-starts)
+		require.Equal(t, int32(3), <-starts)
+		require.Len(t, stream.Results(), 2)
+		require.Equal(t, int32(3), atomic.LoadInt32(&started))
+
+		first := <-stream.Results()
+		require.Equal(t, 1, first.Value)
+		require.Equal(t, int32(4), <-starts)
+
+		count := 1
+		for range stream.Results() {
+			count++
+		}
+		require.Equal(t, 5, count)
+	})
+
+	t.Run("should stop early", func(t *testing.T) {
+		var cancelled int32
+		starts := make(chan struct{}, 2)
+		waiting := func(ctx context.Context) (int, error) {
+			starts <- struct{}{}
+			<-ctx.Done()
+			atomic.AddInt32(&cancelled, 1)
+			return 0, ctx.Err()
+		}
+
+		stream := NewGroupOf([]Task[int]{waiting, waiting, value(1)}).Stream(ctx, 0)
+		<-starts
+		<-starts
+
+		r := <-stream.Results()
+		require.Equal(t, 1, r.Value)
+		stream.Stop()
+
+		// drain what was queued before Stop until the channel closes
+		for range stream.Results() {
+		}
+		_, ok := <-stream.Results()
+		require.False(t, ok)
+		require.Equal(t, int32(2), atomic.LoadInt32(&cancelled))
+		stream.Stop()
+	})
+
+	t.Run("should propagate panics from next", func(t *testing.T) {
+		stream := NewGroupOf([]Task[int]{panicking}, WithPanicPropagation()).Stream(ctx, 1)
+		require.Panics(t, func() { stream.Next() })
+	})
+}
@@ -0,0 +1,81 @@
+package promise
+
+import (
+	"context"
+	"sync"
+)
+
+// Stream delivers the results of a group in completion order while its tasks run.
+type Stream[T any] struct {
+	results chan Result[T]
+	stop    chan struct{}
+	once    sync.Once
+	cancel  context.CancelFunc
+}
+
+// Stream executes the tasks in the background and sends their results on a channel as they
+// complete. Up to buffer results are queued, past that tasks block until the consumer catches up,
+// and with WithConcurrency no new task starts meanwhile. The channel is closed once every task
+// returned.
+func (g *GroupOf[T]) Stream(ctx context.Context, buffer int) *Stream[T] {
+	if buffer < 0 {
+		buffer = 0
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Stream[T]{
+		results: make(chan Result[T], buffer),
+		stop:    make(chan struct{}),
+		cancel:  cancel,
+	}
+
+	go func() {
+		defer cancel()
+		defer close(s.results)
+
+		g.execute(ctx, func(r Result[T]) {
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+
+			select {
+			case s.results <- r:
+			case <-s.stop:
+			}
+		})
+	}()
+
+	return s
+}
+
+// Results returns the channel receiving the results. Panics are received as Internal errors, use
+// Next to have them propagated with WithPanicPropagation.
+func (s *Stream[T]) Results() <-chan Result[T] {
+	return s.results
+}
+
+// Next waits for the next result, the flag is false once every result was received. With
+// WithPanicPropagation it stops the stream and panics again when a task panicked.
+func (s *Stream[T]) Next() (Result[T], bool) {
+	r, ok := <-s.results
+	if ok && r.recovered != nil {
+		s.Stop()
+		panic(r.recovered)
+	}
+
+	return r, ok
+}
+
+// Stop cancels the context given to the tasks, so the ones not started yet are skipped, and drops
+// the results of the tasks that complete afterwards. A task already waiting for room when Stop is
+// called may still get its result queued. Results already queued can still be received and the
+// channel is closed once the running tasks return. It is safe to call more than once.
+func (s *Stream[T]) Stop() {
+	s.once.Do(func() {
+		// closed first so the results of the cancelled tasks are dropped
+		close(s.stop)
+		s.cancel()
+	})
+}
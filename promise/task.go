@@ -0,0 +1,106 @@
+package promise
+
+import (
+	"context"
+	"time"
+
+	"github.com/mtavano/devkit/errors"
+)
+
+const (
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMultiplier = 2
+)
+
+// RetryPolicy sets how a task is attempted again after failing.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, zero means no cap.
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each retry. Defaults to 2.
+	Multiplier float64
+	// Retryable reports whether an error is worth another attempt. Defaults to errors.IsRetryable,
+	// so timeouts, rate limits and unavailable dependencies are retried.
+	Retryable func(error) bool
+}
+
+// WithTimeout returns the task with its context cancelled after d. Errors returned once d elapsed
+// are tagged with the Timeout kind, so they are retryable.
+func (t Task[T]) WithTimeout(d time.Duration) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		res, err := t(ctx)
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			return res, errors.WithKind(errors.Wrapf(err, "promise: Task.WithTimeout %s elapsed", d), errors.Timeout)
+		}
+
+		return res, err
+	}
+}
+
+// WithRetry returns the task attempted again while it fails with a retryable error, waiting an
+// exponential backoff between attempts. It stops when the context is done and returns the last
+// error.
+func (t Task[T]) WithRetry(policy RetryPolicy) Task[T] {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultRetryBackoff
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = defaultRetryMultiplier
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = errors.IsRetryable
+	}
+
+	return func(ctx context.Context) (T, error) {
+		backoff := policy.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			res, err := t(ctx)
+			if err == nil || attempt >= policy.MaxAttempts || !policy.Retryable(err) {
+				return res, err
+			}
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return res, err
+			case <-timer.C:
+			}
+
+			backoff = time.Duration(float64(backoff) * policy.Multiplier)
+			if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+				backoff = policy.MaxBackoff
+			}
+		}
+	}
+}
+
+// WithFallback returns the task resolving to v when it fails, unless the context given to it is
+// done, which means the group already gave up on it.
+func (t Task[T]) WithFallback(v T) Task[T] {
+	return t.WithFallbackFunc(func(context.Context, error) (T, error) {
+		return v, nil
+	})
+}
+
+// WithFallbackFunc returns the task calling fn with the error when it fails, unless the context
+// given to it is done. fn may fail too.
+func (t Task[T]) WithFallbackFunc(fn func(ctx context.Context, err error) (T, error)) Task[T] {
+	return func(ctx context.Context) (T, error) {
+		res, err := t(ctx)
+		if err == nil || ctx.Err() != nil {
+			return res, err
+		}
+
+		return fn(ctx, err)
+	}
+}
@@ -0,0 +1,131 @@
+package reporter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mtavano/devkit/errors"
+)
+
+// Event is a Sentry compatible error event.
+type Event struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Message     string                 `json:"message,omitempty"`
+	Exception   *ExceptionList         `json:"exception,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Fingerprint []string               `json:"fingerprint,omitempty"`
+}
+
+type ExceptionList struct {
+	Values []*Exception `json:"values"`
+}
+
+// Exception is a single error of the chain. Sentry expects the chain ordered from the root cause
+// to the outermost error.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace holds the frames ordered from the outermost call to the innermost one, as Sentry
+// expects.
+type Stacktrace struct {
+	Frames []*StackFrame `json:"frames"`
+}
+
+type StackFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// NewEvent builds the event of err from its ErrorCause, so the message and values are already
+// redacted. The merry values and fields are sent as extra data and the fingerprint groups the
+// event the same way errors.Fingerprint does.
+func NewEvent(err error) *Event {
+	cause := errors.GetCauseFromError(err)
+
+	event := &Event{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC(),
+		Level:       "error",
+		Platform:    "go",
+		Message:     cause.Error(),
+		Tags:        map[string]string{"kind": cause.Kind.String(), "http_code": strconv.Itoa(errors.HTTPCode(err))},
+		Fingerprint: []string{errors.Fingerprint(err)},
+	}
+
+	if len(cause.Values) > 0 {
+		event.Extra = cause.Values
+	}
+
+	// the outermost error goes last and carries the stack
+	exceptions := []*Exception{}
+	for wrapped := cause.Wrapped; wrapped != nil; wrapped = wrapped.Wrapped {
+		exceptions = append([]*Exception{{Type: exceptionType(wrapped), Value: wrapped.Error()}}, exceptions...)
+	}
+	exceptions = append(exceptions, &Exception{
+		Type:       exceptionType(cause),
+		Value:      cause.Error(),
+		Stacktrace: newStacktrace(cause.Trace),
+	})
+	event.Exception = &ExceptionList{Values: exceptions}
+
+	return event
+}
+
+func exceptionType(cause *errors.ErrorCause) string {
+	if cause.Kind == errors.Unknown {
+		return "error"
+	}
+
+	return cause.Kind.String()
+}
+
+func newStacktrace(trace errors.Trace) *Stacktrace {
+	if len(trace) == 0 {
+		return nil
+	}
+
+	modulePath := errors.GetTraceOptions().ModulePath
+	frames := make([]*StackFrame, 0, len(trace))
+	for i := len(trace) - 1; i >= 0; i-- {
+		frame := trace[i]
+		module, function := errors.SplitFunction(frame.Function)
+		frames = append(frames, &StackFrame{
+			Function: function,
+			Module:   module,
+			Filename: frame.File,
+			Lineno:   frame.Line,
+			InApp:    inApp(module, modulePath),
+		})
+	}
+
+	return &Stacktrace{Frames: frames}
+}
+
+// inApp reports whether module is the module at modulePath or one of its packages.
+func inApp(module, modulePath string) bool {
+	return modulePath != "" && (module == modulePath || strings.HasPrefix(module, modulePath+"/"))
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
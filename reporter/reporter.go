@@ -0,0 +1,338 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	nativehttp "net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	devkithttp "github.com/mtavano/devkit/clients/http"
+	"github.com/mtavano/devkit/errors"
+	"github.com/mtavano/devkit/promise"
+)
+
+const (
+	defaultBatchSize     = 10
+	defaultFlushInterval = 5 * time.Second
+	defaultQueueSize     = 100
+	defaultSendTimeout   = 10 * time.Second
+	defaultConcurrency   = 4
+
+	envelopeContentType = "application/x-sentry-envelope"
+	sentryClient        = "devkit-reporter/1.0"
+)
+
+var (
+	ErrInvalidDSN = errors.WithKind(errors.New("reporter: invalid dsn"), errors.Invalid)
+	ErrClosed     = errors.New("reporter: reporter closed")
+)
+
+type Options struct {
+	// DSN is the Sentry style project DSN: https://<key>@<host>/<project_id>.
+	DSN         string
+	Environment string
+	Release     string
+
+	// SampleRate is the fraction of the captured errors that are sent, between 0 and 1. Unset
+	// sends them all.
+	SampleRate *float64
+	// BatchSize is the number of queued events that triggers a send. Each event of a batch is
+	// still its own request, sent concurrently with the others up to SendConcurrency at a time.
+	// Defaults to 10.
+	BatchSize int
+	// SendConcurrency bounds the requests in flight while sending a batch. Defaults to 4.
+	SendConcurrency int
+	// FlushInterval is the longest an event waits in the queue. Defaults to 5s.
+	FlushInterval time.Duration
+	// QueueSize bounds the pending events, Capture drops events while it is full. Defaults to 100.
+	QueueSize int
+
+	// HTTPClient sends the events. Defaults to a client over http.DefaultClient allowing 10
+	// requests per second.
+	HTTPClient *devkithttp.Client
+}
+
+// Reporter sends errors as Sentry events from a background worker.
+type Reporter struct {
+	endpoint   string
+	dsn        string
+	authHeader string
+
+	environment   string
+	release       string
+	sampleRate    float64
+	batchSize     int
+	concurrency   int
+	flushInterval time.Duration
+	client        *devkithttp.Client
+
+	queue   chan *Event
+	flushCh chan chan error
+	closeCh chan struct{}
+	doneCh  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	sample func() float64
+}
+
+func New(opts *Options) (*Reporter, error) {
+	endpoint, key, err := parseDSN(opts.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reporter{
+		endpoint:      endpoint,
+		dsn:           opts.DSN,
+		authHeader:    fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", sentryClient, key),
+		environment:   opts.Environment,
+		release:       opts.Release,
+		sampleRate:    1,
+		batchSize:     opts.BatchSize,
+		concurrency:   opts.SendConcurrency,
+		flushInterval: opts.FlushInterval,
+		client:        opts.HTTPClient,
+		flushCh:       make(chan chan error),
+		closeCh:       make(chan struct{}),
+		doneCh:        make(chan struct{}),
+		sample:        rand.Float64,
+	}
+
+	if opts.SampleRate != nil {
+		r.sampleRate = math.Max(0, math.Min(1, *opts.SampleRate))
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = defaultBatchSize
+	}
+	if r.concurrency <= 0 {
+		r.concurrency = defaultConcurrency
+	}
+	if r.flushInterval <= 0 {
+		r.flushInterval = defaultFlushInterval
+	}
+	if r.client == nil {
+		r.client = devkithttp.NewClient(&devkithttp.Options{MaxRequest: 10, WindowInSeconds: 10}, nativehttp.DefaultClient)
+	}
+
+	queueSize := opts.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	r.queue = make(chan *Event, queueSize)
+
+	go r.run()
+
+	return r, nil
+}
+
+// parseDSN returns the envelope endpoint and the public key of dsn.
+func parseDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", errors.Wrapf(ErrInvalidDSN, "reporter: parseDSN url.Parse error: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", errors.Wrapf(ErrInvalidDSN, "reporter: parseDSN unsupported scheme %q", u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return "", "", errors.Wrap(ErrInvalidDSN, "reporter: parseDSN missing public key")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	project := path[idx+1:]
+	if project == "" {
+		return "", "", errors.Wrap(ErrInvalidDSN, "reporter: parseDSN missing project id")
+	}
+
+	endpoint := fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path[:idx], project)
+	return endpoint, u.User.Username(), nil
+}
+
+// Capture queues err to be sent. It returns false when the event was sampled out, the queue is
+// full or the reporter is closed, as reporting must never block the caller.
+func (r *Reporter) Capture(err error) bool {
+	if err == nil || r.sample() >= r.sampleRate {
+		return false
+	}
+
+	event := NewEvent(err)
+	event.Environment = r.environment
+	event.Release = r.release
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		return false
+	}
+
+	select {
+	case r.queue <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// Flush sends the queued events and waits for them to be delivered.
+func (r *Reporter) Flush(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	select {
+	case r.flushCh <- done:
+	case <-r.doneCh:
+		return ErrClosed
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "reporter: Reporter.Flush error")
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "reporter: Reporter.Flush error")
+	}
+}
+
+// Close stops accepting events, sends the queued ones and waits for the worker to finish.
+func (r *Reporter) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.closeCh)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.doneCh:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "reporter: Reporter.Close error")
+	}
+}
+
+func (r *Reporter) run() {
+	defer close(r.doneCh)
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Event, 0, r.batchSize)
+	send := func() error {
+		err := r.send(batch)
+		batch = batch[:0]
+		return err
+	}
+	drain := func() {
+		for {
+			select {
+			case event := <-r.queue:
+				batch = append(batch, event)
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case event := <-r.queue:
+			batch = append(batch, event)
+			if len(batch) >= r.batchSize {
+				_ = send()
+			}
+		case <-ticker.C:
+			_ = send()
+		case done := <-r.flushCh:
+			drain()
+			done <- send()
+		case <-r.closeCh:
+			drain()
+			_ = send()
+			return
+		}
+	}
+}
+
+// send delivers the batch, one envelope per event as ingestion accepts a single event each, and
+// joins the errors of the failed ones.
+func (r *Reporter) send(batch []*Event) error {
+	tasks := make([]promise.Task[struct{}], 0, len(batch))
+	for _, event := range batch {
+		event := event
+		tasks = append(tasks, func(context.Context) (struct{}, error) {
+			return struct{}{}, r.sendEvent(event)
+		})
+	}
+
+	_, errs, _ := promise.NewGroupOf(tasks, promise.WithConcurrency(r.concurrency)).ExecAll(context.Background())
+	return errors.Join(errs...)
+}
+
+func (r *Reporter) sendEvent(event *Event) error {
+	body, err := r.envelope(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSendTimeout)
+	defer cancel()
+
+	req, err := nativehttp.NewRequestWithContext(ctx, nativehttp.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "reporter: Reporter.sendEvent nativehttp.NewRequestWithContext error")
+	}
+	req.Header.Set("Content-Type", envelopeContentType)
+	req.Header.Set("X-Sentry-Auth", r.authHeader)
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "reporter: Reporter.sendEvent r.client.Do error")
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.WithKind(
+			errors.New(fmt.Sprintf("reporter: Reporter.sendEvent unexpected status code %d", res.StatusCode)),
+			errors.KindFromHTTPCode(res.StatusCode),
+		)
+	}
+
+	return nil
+}
+
+// envelope encodes event with its envelope and item headers, one JSON document per line.
+func (r *Reporter) envelope(event *Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, errors.Wrap(err, "reporter: Reporter.envelope json.Marshal error")
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	_ = enc.Encode(map[string]interface{}{
+		"event_id": event.EventID,
+		"sent_at":  time.Now().UTC(),
+		"dsn":      r.dsn,
+	})
+	_ = enc.Encode(map[string]interface{}{
+		"type":   "event",
+		"length": len(payload),
+	})
+	buf.Write(payload)
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,260 @@
+package reporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mtavano/devkit/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type ingestServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	auth    []string
+	headers []map[string]interface{}
+	events  []map[string]interface{}
+}
+
+func newIngestServer(t *testing.T, status int) *ingestServer {
+	s := &ingestServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/42/envelope/", r.URL.Path)
+		require.Equal(t, envelopeContentType, r.Header.Get("Content-Type"))
+
+		scanner := bufio.NewScanner(r.Body)
+		lines := []map[string]interface{}{}
+		for scanner.Scan() {
+			line := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+		require.Len(t, lines, 3)
+
+		s.mu.Lock()
+		s.auth = append(s.auth, r.Header.Get("X-Sentry-Auth"))
+		s.headers = append(s.headers, lines[1])
+		s.events = append(s.events, lines[2])
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *ingestServer) received() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]map[string]interface{}{}, s.events...)
+}
+
+func (s *ingestServer) dsn() string {
+	return strings.Replace(s.URL, "http://", "http://public@", 1) + "/42"
+}
+
+func Test_parseDSN(t *testing.T) {
+	testCases := []struct {
+		name             string
+		dsn              string
+		expectedEndpoint string
+		expectedKey      string
+		expectedErr      bool
+	}{
+		{
+			name:             "should build the endpoint",
+			dsn:              "https://abc@o1.ingest.example.com/42",
+			expectedEndpoint: "https://o1.ingest.example.com/api/42/envelope/",
+			expectedKey:      "abc",
+		},
+		{
+			name:             "should keep the path prefix",
+			dsn:              "http://abc@localhost:9000/sentry/7",
+			expectedEndpoint: "http://localhost:9000/sentry/api/7/envelope/",
+			expectedKey:      "abc",
+		},
+		{
+			name:        "should fail without key",
+			dsn:         "https://o1.ingest.example.com/42",
+			expectedErr: true,
+		},
+		{
+			name:        "should fail without project",
+			dsn:         "https://abc@o1.ingest.example.com/",
+			expectedErr: true,
+		},
+		{
+			name:        "should fail with an unknown scheme",
+			dsn:         "ftp://abc@o1.ingest.example.com/42",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint, key, err := parseDSN(tc.dsn)
+			if tc.expectedErr {
+				require.True(t, errors.Is(err, ErrInvalidDSN))
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedEndpoint, endpoint)
+			require.Equal(t, tc.expectedKey, key)
+		})
+	}
+}
+
+func Test_NewEvent(t *testing.T) {
+	err := errors.Wrap(
+		errors.WithField(errors.WithKind(errors.New("account acc_1 not found"), errors.NotFound), "fintoc_account_id", "acc_1"),
+		"fintoc: Client.GetAccountMovements error",
+	)
+
+	event := NewEvent(err)
+	require.Len(t, event.EventID, 32)
+	require.Equal(t, "error", event.Level)
+	require.Equal(t, "go", event.Platform)
+	require.Equal(t, []string{errors.Fingerprint(err)}, event.Fingerprint)
+	require.Equal(t, "not_found", event.Tags["kind"])
+	require.Equal(t, "404", event.Tags["http_code"])
+	require.Equal(t, "acc_1", event.Extra["fintoc_account_id"])
+
+	values := event.Exception.Values
+	require.Len(t, values, 2)
+	require.Equal(t, "account acc_1 not found", values[0].Value)
+	require.Nil(t, values[0].Stacktrace)
+	require.Equal(t, "not_found", values[1].Type)
+	require.Equal(t, err.Error(), values[1].Value)
+
+	// the innermost frame, this test, goes last
+	frames := values[1].Stacktrace.Frames
+	require.NotEmpty(t, frames)
+	last := frames[len(frames)-1]
+	require.Equal(t, "Test_NewEvent", last.Function)
+	require.Equal(t, "github.com/mtavano/devkit/reporter", last.Module)
+	require.Equal(t, "reporter/reporter_test.go", last.Filename)
+}
+
+func Test_inApp(t *testing.T) {
+	testCases := []struct {
+		name     string
+		module   string
+		expected bool
+	}{
+		{name: "should match the module", module: "github.com/mtavano/devkit", expected: true},
+		{name: "should match its packages", module: "github.com/mtavano/devkit/reporter", expected: true},
+		{name: "should not match sibling modules", module: "github.com/mtavano/devkitx", expected: false},
+		{name: "should not match other modules", module: "github.com/ansel1/merry/v2", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, inApp(tc.module, "github.com/mtavano/devkit"))
+		})
+	}
+
+	require.False(t, inApp("github.com/mtavano/devkit", ""))
+}
+
+func Test_Reporter(t *testing.T) {
+	t.Run("should flush batches and redact values", func(t *testing.T) {
+		server := newIngestServer(t, http.StatusOK)
+		r, err := New(&Options{DSN: server.dsn(), Environment: "test", Release: "v1.2.3", BatchSize: 2, FlushInterval: time.Hour})
+		require.NoError(t, err)
+
+		require.True(t, r.Capture(errors.WithSensitiveField(errors.New("bad request"), "body", "secret")))
+		require.True(t, r.Capture(errors.New("token eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig leaked")))
+		require.True(t, r.Capture(errors.New("third")))
+
+		require.NoError(t, r.Flush(context.Background()))
+
+		// events of a batch are sent concurrently
+		events := map[string]map[string]interface{}{}
+		for _, event := range server.received() {
+			events[event["message"].(string)] = event
+		}
+		require.Len(t, events, 3)
+		require.Equal(t, "test", events["bad request"]["environment"])
+		require.Equal(t, "v1.2.3", events["bad request"]["release"])
+		require.Equal(t, errors.Redacted, events["bad request"]["extra"].(map[string]interface{})["body"])
+		require.Contains(t, events, "token [REDACTED] leaked")
+		require.Equal(t, "event", server.headers[0]["type"])
+		require.Contains(t, server.auth[0], "sentry_key=public")
+
+		require.NoError(t, r.Close(context.Background()))
+	})
+
+	t.Run("should send pending events on close", func(t *testing.T) {
+		server := newIngestServer(t, http.StatusOK)
+		r, err := New(&Options{DSN: server.dsn(), FlushInterval: time.Hour})
+		require.NoError(t, err)
+
+		require.True(t, r.Capture(errors.New("pending")))
+		require.NoError(t, r.Close(context.Background()))
+		require.Len(t, server.received(), 1)
+
+		require.False(t, r.Capture(errors.New("after close")))
+		require.True(t, errors.Is(r.Flush(context.Background()), ErrClosed))
+	})
+
+	t.Run("should report rejected events on flush", func(t *testing.T) {
+		server := newIngestServer(t, http.StatusTooManyRequests)
+		r, err := New(&Options{DSN: server.dsn(), FlushInterval: time.Hour})
+		require.NoError(t, err)
+		defer r.Close(context.Background())
+
+		require.True(t, r.Capture(errors.New("rejected")))
+		err = r.Flush(context.Background())
+		require.Error(t, err)
+		require.Equal(t, errors.RateLimited, errors.KindOf(err))
+	})
+
+	t.Run("should drop events when the queue is full", func(t *testing.T) {
+		// no worker drains the queue
+		r := &Reporter{queue: make(chan *Event, 1), sampleRate: 1, sample: func() float64 { return 0 }}
+
+		require.True(t, r.Capture(errors.New("queued")))
+		require.False(t, r.Capture(errors.New("dropped")))
+	})
+
+	t.Run("should sample events", func(t *testing.T) {
+		server := newIngestServer(t, http.StatusOK)
+		rate := 0.5
+		r, err := New(&Options{DSN: server.dsn(), SampleRate: &rate, FlushInterval: time.Hour})
+		require.NoError(t, err)
+
+		rolls := []float64{0.1, 0.9}
+		r.sample = func() float64 {
+			roll := rolls[0]
+			rolls = rolls[1:]
+			return roll
+		}
+
+		require.True(t, r.Capture(errors.New("kept")))
+		require.False(t, r.Capture(errors.New("sampled out")))
+		require.NoError(t, r.Close(context.Background()))
+		require.Len(t, server.received(), 1)
+	})
+
+	t.Run("should send nothing with a zero sample rate", func(t *testing.T) {
+		server := newIngestServer(t, http.StatusOK)
+		rate := 0.0
+		r, err := New(&Options{DSN: server.dsn(), SampleRate: &rate, FlushInterval: time.Hour})
+		require.NoError(t, err)
+
+		require.False(t, r.Capture(errors.New("sampled out")))
+		require.NoError(t, r.Close(context.Background()))
+		require.Empty(t, server.received())
+	})
+}